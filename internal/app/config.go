@@ -0,0 +1,54 @@
+package app
+
+import (
+	"errors"
+	"os"
+)
+
+// envDev - APP_ENV локальной разработки: только в нём разрешён ключ JWT из cmd/issue-token
+const envDev = "dev"
+
+// devJWTKey - ключ по умолчанию у cmd/issue-token; известен всем, кто видел репозиторий,
+// поэтому сервер не принимает его вне APP_ENV=dev
+const devJWTKey = "local-dev-jwt-key"
+
+var (
+	errJWTKeyMissing = errors.New("JWT_KEY is empty")
+	errJWTKeyIsDev   = errors.New("JWT_KEY is the public development key, set APP_ENV=dev to use it")
+)
+
+// config - настройки, которые отличаются между окружениями: берутся из переменных окружения,
+// чтобы адреса и секреты не лежали в коде
+type config struct {
+	// CRM_URL / CRM_TOKEN: пустой CRM_URL — CRM не подключена, выгрузка не запускается
+	// и crm_outbox копится в PENDING до её подключения
+	CRMURL   string
+	CRMToken string
+	// INTAKE_SECRET: ключ подписи заявок с формы; пустой — /applications/intake отвечает 503
+	IntakeSecret string
+	// JWT_KEY: ключ HS256 для bearer токенов, обязателен
+	JWTKey string
+	// APP_ENV: dev для локальной разработки, иначе считается боевым окружением
+	AppEnv string
+}
+
+func loadConfig() (config, error) {
+	return parseConfig(os.Getenv)
+}
+
+func parseConfig(getenv func(string) string) (config, error) {
+	cfg := config{
+		CRMURL:       getenv("CRM_URL"),
+		CRMToken:     getenv("CRM_TOKEN"),
+		IntakeSecret: getenv("INTAKE_SECRET"),
+		JWTKey:       getenv("JWT_KEY"),
+		AppEnv:       getenv("APP_ENV"),
+	}
+	switch {
+	case cfg.JWTKey == "":
+		return cfg, errJWTKeyMissing
+	case cfg.JWTKey == devJWTKey && cfg.AppEnv != envDev:
+		return cfg, errJWTKeyIsDev
+	}
+	return cfg, nil
+}
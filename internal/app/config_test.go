@@ -0,0 +1,31 @@
+package app
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseConfigJWTKey(t *testing.T) {
+	cases := []struct {
+		name string
+		env  map[string]string
+		want error
+	}{
+		{"нет ключа", map[string]string{"APP_ENV": envDev}, errJWTKeyMissing},
+		{"ключ разработки без APP_ENV", map[string]string{"JWT_KEY": devJWTKey}, errJWTKeyIsDev},
+		{"ключ разработки в проде", map[string]string{"JWT_KEY": devJWTKey, "APP_ENV": "prod"}, errJWTKeyIsDev},
+		{"ключ разработки в dev", map[string]string{"JWT_KEY": devJWTKey, "APP_ENV": envDev}, nil},
+		{"свой ключ", map[string]string{"JWT_KEY": "6f1c0a9e4b7d2e8f3a5c1b0d9e8f7a6b"}, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, err := parseConfig(func(k string) string { return c.env[k] })
+			if !errors.Is(err, c.want) {
+				t.Fatalf("parseConfig = %v, want %v", err, c.want)
+			}
+			if err == nil && cfg.JWTKey != c.env["JWT_KEY"] {
+				t.Errorf("JWTKey = %q", cfg.JWTKey)
+			}
+		})
+	}
+}
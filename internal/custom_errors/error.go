@@ -1,12 +1,74 @@
 package custom_errors
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
 
 var (
-	ErrFailedToOpenFile = errors.New("failed to open file")
-	ErrFailedToReadFile = errors.New("failed to read file")
-	ErrInvalidXLSX      = errors.New("invalid xlsx")
-	ErrNoXLSXSheets     = errors.New("no xlsx sheets found")
-	ErrNoXLSXData       = errors.New("no xlsx data")
-	ErrTimeFormat       = errors.New("error time format")
+	ErrFailedToOpenFile  = errors.New("failed to open file")
+	ErrFailedToReadFile  = errors.New("failed to read file")
+	ErrInvalidXLSX       = errors.New("invalid xlsx")
+	ErrInvalidCSV        = errors.New("invalid csv")
+	ErrUnsupportedFormat = errors.New("unsupported file format")
+	ErrNoXLSXSheets      = errors.New("no xlsx sheets found")
+	ErrNoXLSXData        = errors.New("no xlsx data")
+	ErrTimeFormat        = errors.New("error time format")
+	ErrImportQueueFull   = errors.New("import queue is full")
+	ErrDuplicateImport   = errors.New("file was already imported")
+	ErrFileTooLarge      = errors.New("file is too large")
+
+	ErrInvalidImportProfile = errors.New("invalid import profile")
+	ErrMissingColumns       = errors.New("required columns are missing")
+
+	ErrSheetNotFound       = errors.New("sheet not found")
+	ErrNoMatchingSheets    = errors.New("no sheet matches import profile")
+	ErrSheetsNotApplicable = errors.New("sheet selection is only supported for xlsx")
 )
+
+// DuplicateImportError - такой же файл (по sha256) уже импортировался
+type DuplicateImportError struct {
+	ImportID  string
+	Status    string
+	CreatedAt time.Time
+}
+
+func (e *DuplicateImportError) Error() string {
+	return fmt.Sprintf("%v: import %s (%s) at %s", ErrDuplicateImport, e.ImportID, e.Status, e.CreatedAt.Format(time.RFC3339))
+}
+
+func (e *DuplicateImportError) Unwrap() error {
+	return ErrDuplicateImport
+}
+
+// MissingColumnsError - в заголовке файла нет обязательных столбцов профиля
+type MissingColumnsError struct {
+	Sheet   string   // лист xlsx, если проверялся не единственный
+	Columns []string // ожидаемые заголовки (первый алиас каждого поля)
+}
+
+func (e *MissingColumnsError) Error() string {
+	if e.Sheet != "" {
+		return fmt.Sprintf("%v: sheet %q: %s", ErrMissingColumns, e.Sheet, strings.Join(e.Columns, ", "))
+	}
+	return fmt.Sprintf("%v: %s", ErrMissingColumns, strings.Join(e.Columns, ", "))
+}
+
+func (e *MissingColumnsError) Unwrap() error {
+	return ErrMissingColumns
+}
+
+// SheetNotFoundError - в книге xlsx нет листа, выбранного для импорта
+type SheetNotFoundError struct {
+	Sheet string
+}
+
+func (e *SheetNotFoundError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrSheetNotFound, e.Sheet)
+}
+
+func (e *SheetNotFoundError) Unwrap() error {
+	return ErrSheetNotFound
+}
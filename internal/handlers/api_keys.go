@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/services"
+	"go.uber.org/zap"
+)
+
+// curl -X POST http://localhost:8080/api/v1/api-keys -H "Authorization: Bearer <jwt>" \
+// -H "Content-Type: application/json" -d '{"name":"crm-sync","expires_at":"2026-12-31T00:00:00Z"}'
+// ключ в ответе показывается один раз
+func (h *Handler) IssueAPIKey(ctx *gin.Context) {
+	var req models.IssueAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "невалидный json"})
+		return
+	}
+
+	res, err := h.service.IssueAPIKey(ctx.Request.Context(), currentUser(ctx), req)
+	if err != nil {
+		if errors.Is(err, services.ErrAPIKeyNameRequired) || errors.Is(err, services.ErrAPIKeyExpiresInPast) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("h.service.IssueAPIKey: ", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, res)
+}
+
+// curl http://localhost:8080/api/v1/api-keys -H "Authorization: Bearer <jwt>"
+func (h *Handler) ListAPIKeys(ctx *gin.Context) {
+	res, err := h.service.ListAPIKeys(ctx.Request.Context(), currentUser(ctx))
+	if err != nil {
+		h.logger.Error("h.service.ListAPIKeys: ", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// curl -X DELETE http://localhost:8080/api/v1/api-keys/<uuid> -H "Authorization: Bearer <jwt>"
+func (h *Handler) RevokeAPIKey(ctx *gin.Context) {
+	keyID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid key_id"})
+		return
+	}
+
+	res, err := h.service.RevokeAPIKey(ctx.Request.Context(), currentUser(ctx), keyID)
+	if err != nil {
+		if services.IsAPIKeyNotFound(err) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "ключ не найден или уже отозван"})
+			return
+		}
+		h.logger.Error("h.service.RevokeAPIKey: ", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
@@ -9,7 +9,9 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/services"
 )
 
 // curl "http://localhost:8080/api/v1/applications?limit=20&offset=0&status=NEW,IN_REVIEW&q=Петр"
@@ -30,6 +32,14 @@ func (h *Handler) ListApplications(ctx *gin.Context) {
 		ImportID:    ctx.Query("import_id"),
 	}
 
+	if v := strings.TrimSpace(ctx.Query("status_changed_by")); v != "" {
+		if _, err := uuid.Parse(v); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid status_changed_by"})
+			return
+		}
+		p.StatusChangedBy = v
+	}
+
 	// status=NEW,INVITED,CRM_QUEUED...
 	if s := strings.TrimSpace(ctx.Query("status")); s != "" {
 		parts := strings.Split(s, ",")
@@ -79,6 +89,36 @@ func (h *Handler) ListApplications(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, res)
 }
 
+// curl -X POST http://localhost:8080/api/v1/applications/reprocess -H "Content-Type: application/json" -d '{"import_id":"<uuid>"}'
+// curl -X POST http://localhost:8080/api/v1/applications/reprocess -H "Content-Type: application/json" -d '{"all":true,"apply":true}'
+// повторный разбор raw_row текущим парсером; без apply — только список изменений
+func (h *Handler) ReprocessApplications(ctx *gin.Context) {
+	var req models.ReprocessRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil || (!req.All && req.ImportID == "") {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "import_id или all обязательны"})
+		return
+	}
+	if !req.All {
+		if _, err := uuid.Parse(req.ImportID); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid import_id"})
+			return
+		}
+	}
+
+	res, err := h.service.ReprocessApplications(ctx.Request.Context(), req)
+	if err != nil {
+		if services.IsImportNotFound(err) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "импорт не найден"})
+			return
+		}
+		h.logger.Error("h.service.ReprocessApplications: ", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
 func parseInt(s string, def int) int {
 	if strings.TrimSpace(s) == "" {
 		return def
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/services"
+	"go.uber.org/zap"
+)
+
+// curl "http://localhost:8080/api/v1/candidates/duplicates?limit=20&offset=0"
+func (h *Handler) ListDuplicateCandidates(ctx *gin.Context) {
+	res, err := h.service.ListDuplicateCandidates(ctx.Request.Context(), parseInt(ctx.Query("limit"), 50), parseInt(ctx.Query("offset"), 0))
+	if err != nil {
+		h.logger.Error("h.service.ListDuplicateCandidates: ", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+//curl -X POST http://localhost:8080/api/v1/candidates/merge \
+//-H "Content-Type: application/json" \
+//-d '{"source_candidate_id":"<uuid>","target_candidate_id":"<uuid>"}'
+
+func (h *Handler) MergeCandidates(ctx *gin.Context) {
+	var req models.MergeCandidatesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "невалидный json"})
+		return
+	}
+	if _, err := uuid.Parse(req.SourceCandidateID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid source_candidate_id"})
+		return
+	}
+	if _, err := uuid.Parse(req.TargetCandidateID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid target_candidate_id"})
+		return
+	}
+
+	res, err := h.service.MergeCandidates(ctx.Request.Context(), req, currentUser(ctx))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrSelfMerge):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case services.IsCandidateNotFound(err):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "кандидат не найден"})
+		default:
+			h.logger.Error("h.service.MergeCandidates: ", zap.Error(err))
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
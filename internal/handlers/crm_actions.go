@@ -16,7 +16,7 @@ func (h *Handler) QueueApplicationsToCRM(ctx *gin.Context) {
 		return
 	}
 
-	res, err := h.service.QueueToCRM(ctx.Request.Context(), req)
+	res, err := h.service.QueueToCRM(ctx.Request.Context(), req, currentUser(ctx))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
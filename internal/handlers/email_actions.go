@@ -11,7 +11,7 @@ import (
 )
 
 //curl -X POST http://localhost:8080/api/v1/applications/reject \
-//-H "Content-Type: application/json" \
+//-H "Content-Type: application/json" -H "Authorization: Bearer <jwt или API ключ>" \
 //-d '{"application_ids":["<uuid1>"],"status_reason":"Не подошли по требованиям"}'
 
 func (h *Handler) InviteApplications(ctx *gin.Context) {
@@ -28,7 +28,7 @@ func (h *Handler) InviteApplications(ctx *gin.Context) {
 		}
 	}
 
-	res, err := h.service.Invite(ctx.Request.Context(), req)
+	res, err := h.service.Invite(ctx.Request.Context(), req, currentUser(ctx))
 	if err != nil {
 		h.logger.Error("h.service.Invite: ", zap.Error(err))
 		// template not found => 400
@@ -50,7 +50,7 @@ func (h *Handler) RejectApplications(ctx *gin.Context) {
 		return
 	}
 
-	res, err := h.service.Reject(ctx.Request.Context(), req)
+	res, err := h.service.Reject(ctx.Request.Context(), req, currentUser(ctx))
 	if err != nil {
 		h.logger.Error("h.service.Reject: ", zap.Error(err))
 		if services.IsTemplateNotFound(err) {
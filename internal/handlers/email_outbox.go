@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"go.uber.org/zap"
+)
+
+// curl "http://localhost:8080/api/v1/emails/dead?limit=20&offset=0&created_by=<user uuid>"
+func (h *Handler) ListDeadEmails(ctx *gin.Context) {
+	createdBy := strings.TrimSpace(ctx.Query("created_by"))
+	if createdBy != "" {
+		if _, err := uuid.Parse(createdBy); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid created_by"})
+			return
+		}
+	}
+
+	res, err := h.service.ListDeadEmails(ctx.Request.Context(), parseInt(ctx.Query("limit"), 50), parseInt(ctx.Query("offset"), 0), createdBy)
+	if err != nil {
+		h.logger.Error("h.service.ListDeadEmails: ", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+//curl -X POST http://localhost:8080/api/v1/emails/requeue -H "Content-Type: application/json" -d '{"email_ids":["<uuid1>"]}'
+//curl -X POST http://localhost:8080/api/v1/emails/requeue -H "Content-Type: application/json" -d '{"all":true}'
+
+func (h *Handler) RequeueDeadEmails(ctx *gin.Context) {
+	var req models.RequeueEmailsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil || (!req.All && len(req.EmailIDs) == 0) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "email_ids или all обязательны"})
+		return
+	}
+
+	for _, id := range req.EmailIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid email_id: " + id})
+			return
+		}
+	}
+
+	res, err := h.service.RequeueDeadEmails(ctx.Request.Context(), req, currentUser(ctx))
+	if err != nil {
+		h.logger.Error("h.service.RequeueDeadEmails: ", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// curl -X POST http://localhost:8080/api/v1/emails/<uuid>/requeue
+func (h *Handler) RequeueDeadEmail(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid email_id: " + id})
+		return
+	}
+
+	res, err := h.service.RequeueDeadEmails(ctx.Request.Context(), models.RequeueEmailsRequest{EmailIDs: []string{id}}, currentUser(ctx))
+	if err != nil {
+		h.logger.Error("h.service.RequeueDeadEmails: ", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+		return
+	}
+	if res.Requeued == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "письмо не найдено или не в статусе DEAD"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
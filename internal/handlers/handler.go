@@ -13,21 +13,48 @@ var localAddresses = []string{
 	"http://127.0.0.1:3000",
 }
 
+type Config struct {
+	// IntakeSecret - общий ключ HMAC для подписи заявок с формы; пустой — приём выключен
+	IntakeSecret string
+	// JWTKey - ключ HS256 для проверки bearer токенов
+	JWTKey []byte
+}
+
 type Handler struct {
 	logger  *zap.Logger
 	service *services.Service
+	cfg     Config
 }
 
-func NewHandler(logger *zap.Logger, service *services.Service) *Handler {
-	return &Handler{logger: logger, service: service}
+func NewHandler(logger *zap.Logger, service *services.Service, cfg Config) *Handler {
+	return &Handler{logger: logger, service: service, cfg: cfg}
 }
 
 const (
 	importsXLSX      = "/imports/xlsx"
+	importsCSV       = "/imports/csv"
+	importSheets     = "/imports/sheets"
+	importsList      = "/imports"
+	importByID       = "/imports/:id"
+	importEvents     = "/imports/:id/events"
+	importEventsTok  = "/imports/:id/events/token"
+	importErrorsXLSX = "/imports/:id/errors.xlsx"
 	applicationsList = "/applications"
 	inviteApps       = "/applications/invite"
 	rejectApps       = "/applications/reject"
 	crmQueue         = "/applications/crm/queue"
+	reprocessApps    = "/applications/reprocess"
+	intakeApp        = "/applications/intake"
+	deadEmails       = "/emails/dead"
+	requeueEmails    = "/emails/requeue"
+	requeueEmail     = "/emails/:id/requeue"
+	integrationAudit = "/integration-audit"
+	importProfiles   = "/import-profiles"
+	importProfile    = "/import-profiles/:code"
+	candidateDups    = "/candidates/duplicates"
+	candidatesMerge  = "/candidates/merge"
+	apiKeys          = "/api-keys"
+	apiKeyByID       = "/api-keys/:id"
 )
 
 func (h *Handler) InitRoutes() *gin.Engine {
@@ -36,12 +63,42 @@ func (h *Handler) InitRoutes() *gin.Engine {
 	r.Use(ZapLogger(h.logger))
 	r.Use(CORS(localAddresses))
 
-	api := r.Group("/api/v1")
+	public := r.Group("/api/v1")
+	// заявки с формы подписаны общим ключом (X-Signature), пользователя у них нет
+	public.POST(intakeApp, h.IntakeApplication)
+	// EventSource не передаёт заголовки: здесь можно и ?token= (см. IssueImportEventsToken)
+	public.GET(importEvents, StreamAuth(h.logger, h.service, h.cfg.JWTKey), h.StreamImportEvents)
+
+	api := r.Group("/api/v1", Auth(h.logger, h.service, h.cfg.JWTKey))
 	api.POST(importsXLSX, h.UploadXLSX)
+	api.POST(importsCSV, h.UploadCSV)
+	api.POST(importSheets, h.ListSheets)
+	api.POST(importsList, h.UploadFile)
+	api.GET(importsList, h.ListImports)
+	api.GET(importByID, h.GetImport)
+	api.DELETE(importByID, h.RevertImport)
+	api.POST(importEventsTok, h.IssueImportEventsToken)
+	api.GET(importErrorsXLSX, h.DownloadImportErrors)
 	api.GET(applicationsList, h.ListApplications)
 	api.POST(inviteApps, h.InviteApplications)
 	api.POST(rejectApps, h.RejectApplications)
 	api.POST(crmQueue, h.QueueApplicationsToCRM)
+	api.POST(reprocessApps, h.ReprocessApplications)
+	api.GET(deadEmails, h.ListDeadEmails)
+	api.POST(requeueEmails, h.RequeueDeadEmails)
+	api.POST(requeueEmail, h.RequeueDeadEmail)
+	api.GET(integrationAudit, h.ListIntegrationAudit)
+	api.GET(importProfiles, h.ListImportProfiles)
+	api.POST(importProfiles, h.SaveImportProfile)
+	api.GET(importProfile, h.GetImportProfile)
+	api.GET(candidateDups, h.ListDuplicateCandidates)
+	api.POST(candidatesMerge, h.MergeCandidates)
+
+	// ключами управляют только по JWT
+	keys := api.Group("", RequireJWT())
+	keys.GET(apiKeys, h.ListAPIKeys)
+	keys.POST(apiKeys, h.IssueAPIKey)
+	keys.DELETE(apiKeyByID, h.RevokeAPIKey)
 
 	return r
 }
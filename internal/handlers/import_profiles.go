@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/custom_errors"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/services"
+	"go.uber.org/zap"
+)
+
+// curl http://localhost:8080/api/v1/import-profiles
+func (h *Handler) ListImportProfiles(ctx *gin.Context) {
+	res, err := h.service.ListImportProfiles(ctx.Request.Context())
+	if err != nil {
+		h.logger.Error("h.service.ListImportProfiles: ", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// curl http://localhost:8080/api/v1/import-profiles/default
+func (h *Handler) GetImportProfile(ctx *gin.Context) {
+	res, err := h.service.GetImportProfile(ctx.Request.Context(), ctx.Param("code"))
+	if err != nil {
+		if services.IsImportProfileNotFound(err) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "профиль импорта не найден"})
+			return
+		}
+		h.logger.Error("h.service.GetImportProfile: ", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+//curl -X POST http://localhost:8080/api/v1/import-profiles \
+//-H "Content-Type: application/json" \
+//-d '{"code":"partners","name":"Списки вузов","columns":{"last_name":{"aliases":["Фамилия","Last name"],"required":true},"first_name":{"aliases":["Имя"]},"email":{"aliases":["E-mail"]},"applied_at":{"aliases":["Дата"],"required":true}}}'
+
+func (h *Handler) SaveImportProfile(ctx *gin.Context) {
+	var req models.ImportProfile
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "невалидный json"})
+		return
+	}
+
+	res, err := h.service.SaveImportProfile(ctx.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, custom_errors.ErrInvalidImportProfile) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("h.service.SaveImportProfile: ", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
@@ -3,14 +3,49 @@ package handlers
 import (
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/kurushqosimi/x5-intern-hiring/internal/custom_errors"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/services"
 	"go.uber.org/zap"
+	"io"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// интервал опроса БД для SSE прогресса импорта
+const importEventsInterval = time.Second
+
 // curl -F "file=@пример выгрузки отклика.xlsx" http://localhost:8080/api/v1/imports/xlsx
+// повторная загрузка того же файла: curl -F "file=@..." -F "force=true" http://localhost:8080/api/v1/imports/xlsx
+// предпросмотр без записи в БД: curl -F "file=@..." -F "dry_run=true" http://localhost:8080/api/v1/imports/xlsx
+// другой профиль столбцов: curl -F "file=@..." -F "profile=partners" http://localhost:8080/api/v1/imports/xlsx
+// выбранные листы: curl -F "file=@..." -F "sheets=Москва" -F "sheets=Казань" http://localhost:8080/api/v1/imports/xlsx
+// все листы с подходящим заголовком: curl -F "file=@..." -F "all_sheets=true" http://localhost:8080/api/v1/imports/xlsx
+// повторы определяются по sha256 всего файла без учёта выбранных листов: импорт другого листа
+// уже загруженной книги вернёт 409, пока не передан force=true:
+// curl -F "file=@..." -F "sheets=Казань" -F "force=true" http://localhost:8080/api/v1/imports/xlsx
 
 func (h *Handler) UploadXLSX(ctx *gin.Context) {
+	h.upload(ctx, models.FormatXLSX)
+}
+
+// curl -F "file=@partners.csv" http://localhost:8080/api/v1/imports/csv
+// разделитель (, ; TAB |) и кодировка (UTF-8 / Windows-1251) определяются автоматически
+func (h *Handler) UploadCSV(ctx *gin.Context) {
+	h.upload(ctx, models.FormatCSV)
+}
+
+// curl -F "file=@выгрузка.tsv" http://localhost:8080/api/v1/imports
+// формат определяется по расширению и содержимому файла
+func (h *Handler) UploadFile(ctx *gin.Context) {
+	h.upload(ctx, "")
+}
+
+func (h *Handler) upload(ctx *gin.Context, format string) {
 	fileHeader, err := ctx.FormFile("file")
 	if err != nil {
 		h.logger.Error("file's absent", zap.Error(err))
@@ -18,32 +53,365 @@ func (h *Handler) UploadXLSX(ctx *gin.Context) {
 		return
 	}
 
-	procResult, err := h.service.ProcessXLSX(ctx.Request.Context(), fileHeader)
+	opts := models.ImportOptions{Format: format, UploadedBy: currentUser(ctx)}
+	if opts.Force, err = formBool(ctx, "force"); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid force"})
+		return
+	}
+	if opts.DryRun, err = formBool(ctx, "dry_run"); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run"})
+		return
+	}
+
+	if opts.AllSheets, err = formBool(ctx, "all_sheets"); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid all_sheets"})
+		return
+	}
+
+	opts.Profile = strings.TrimSpace(ctx.DefaultPostForm("profile", ctx.Query("profile")))
+	opts.Sheets = formList(ctx, "sheets")
+	if opts.AllSheets && len(opts.Sheets) > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "укажите либо sheets, либо all_sheets"})
+		return
+	}
+
+	if opts.DryRun {
+		res, err := h.service.DryRunXLSX(ctx.Request.Context(), fileHeader, opts)
+		if err != nil {
+			h.logger.Error("h.service.DryRunXLSX: ", zap.Error(err))
+			h.importError(ctx, err)
+			return
+		}
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+
+	metadata, err := h.service.EnqueueXLSX(ctx.Request.Context(), fileHeader, opts)
+	if err != nil {
+		h.logger.Error("h.service.EnqueueXLSX: ", zap.Error(err))
+		h.importError(ctx, err)
+		return
+	}
+
+	importID := metadata.ImportID.String()
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"import_id":   importID,
+		"file_sha256": metadata.FileSha256,
+		"status":      metadata.Status,
+		"profile":     metadata.ProfileCode,
+		"status_url":  "/api/v1/imports/" + importID,
+		"events_url":  "/api/v1/imports/" + importID + "/events",
+	})
+}
+
+// importError - ответ на ошибку загрузки файла
+func (h *Handler) importError(ctx *gin.Context, err error) {
+	var (
+		dup     *custom_errors.DuplicateImportError
+		missing *custom_errors.MissingColumnsError
+		noSheet *custom_errors.SheetNotFoundError
+	)
+	switch {
+	case errors.As(err, &dup):
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error":      "этот файл уже импортировался (проверяется весь файл, а не выбранные листы), для повторного импорта передайте force=true",
+			"import_id":  dup.ImportID,
+			"status":     dup.Status,
+			"created_at": dup.CreatedAt,
+			"import_url": "/api/v1/imports/" + dup.ImportID,
+		})
+	case errors.As(err, &missing):
+		body := gin.H{
+			"error":           "в файле нет обязательных столбцов",
+			"missing_columns": missing.Columns,
+		}
+		if missing.Sheet != "" {
+			body["sheet"] = missing.Sheet
+		}
+		ctx.JSON(http.StatusUnprocessableEntity, body)
+	case errors.Is(err, custom_errors.ErrNoMatchingSheets):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": "ни на одном листе нет обязательных столбцов"})
+	case errors.As(err, &noSheet):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "в книге нет листа", "sheet": noSheet.Sheet})
+	case errors.Is(err, custom_errors.ErrSheetsNotApplicable):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "выбор листов доступен только для xlsx"})
+	case services.IsImportProfileNotFound(err):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "профиль импорта не найден"})
+	case errors.Is(err, custom_errors.ErrFailedToOpenFile):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "невозможно открыть загруженнный файл"})
+	case errors.Is(err, custom_errors.ErrFailedToReadFile):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "невозможно прочесть файл"})
+	case errors.Is(err, custom_errors.ErrFileTooLarge):
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "файл слишком большой"})
+	case errors.Is(err, custom_errors.ErrInvalidXLSX):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "невалидный xlsx"})
+	case errors.Is(err, custom_errors.ErrNoXLSXSheets):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "xlsx не имеет страниц"})
+	case errors.Is(err, custom_errors.ErrNoXLSXData):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "файл не имеет данных"})
+	case errors.Is(err, custom_errors.ErrInvalidCSV):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "невалидный csv"})
+	case errors.Is(err, custom_errors.ErrUnsupportedFormat):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "формат файла не поддерживается, загрузите xlsx, csv или tsv"})
+	case errors.Is(err, custom_errors.ErrImportQueueFull):
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "очередь импорта переполнена, попробуйте позже"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+	}
+}
+
+// formList - повторяющийся параметр из multipart формы или query
+// (без разбиения по запятой: в именах листов она допустима)
+func formList(ctx *gin.Context, name string) []string {
+	values, ok := ctx.GetPostFormArray(name)
+	if !ok {
+		values = ctx.QueryArray(name)
+	}
+	var out []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// curl -F "file=@партнёры.xlsx" http://localhost:8080/api/v1/imports/sheets
+// листы книги и подходят ли они под профиль (profile — как при загрузке)
+func (h *Handler) ListSheets(ctx *gin.Context) {
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "файл не был загружен"})
+		return
+	}
+
+	profile := strings.TrimSpace(ctx.DefaultPostForm("profile", ctx.Query("profile")))
+	res, err := h.service.ListSheets(ctx.Request.Context(), fileHeader, profile)
+	if err != nil {
+		h.logger.Error("h.service.ListSheets: ", zap.Error(err))
+		h.importError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// formBool - булев параметр из multipart формы или query, по умолчанию false
+func formBool(ctx *gin.Context, name string) (bool, error) {
+	v := strings.TrimSpace(ctx.DefaultPostForm(name, ctx.Query(name)))
+	if v == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(v)
+}
+
+// curl "http://localhost:8080/api/v1/imports?limit=20&status=PARSED,FAILED&created_from=2025-01-01&uploaded_by=<user uuid>"
+func (h *Handler) ListImports(ctx *gin.Context) {
+	p := models.ListImportsParams{
+		Limit:  parseInt(ctx.Query("limit"), 50),
+		Offset: parseInt(ctx.Query("offset"), 0),
+	}
+
+	if s := strings.TrimSpace(ctx.Query("status")); s != "" {
+		for _, x := range strings.Split(s, ",") {
+			x = strings.TrimSpace(x)
+			if x != "" {
+				p.Statuses = append(p.Statuses, x)
+			}
+		}
+	}
+
+	if v := strings.TrimSpace(ctx.Query("uploaded_by")); v != "" {
+		if _, err := uuid.Parse(v); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid uploaded_by"})
+			return
+		}
+		p.UploadedBy = v
+	}
+
+	// created_from / created_to: RFC3339 или YYYY-MM-DD
+	if v := strings.TrimSpace(ctx.Query("created_from")); v != "" {
+		t, err := parseTime(v)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid created_from"})
+			return
+		}
+		p.CreatedFrom = &t
+	}
+	if v := strings.TrimSpace(ctx.Query("created_to")); v != "" {
+		t, err := parseTime(v)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid created_to"})
+			return
+		}
+		p.CreatedTo = &t
+	}
+
+	res, err := h.service.ListImports(ctx.Request.Context(), p)
+	if err != nil {
+		h.logger.Error("h.service.ListImports: ", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// curl http://localhost:8080/api/v1/imports/<uuid>
+func (h *Handler) GetImport(ctx *gin.Context) {
+	importID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid import_id"})
+		return
+	}
+
+	res, err := h.service.GetImport(ctx.Request.Context(), importID)
+	if err != nil {
+		if services.IsImportNotFound(err) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "импорт не найден"})
+			return
+		}
+		h.logger.Error("h.service.GetImport: ", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// curl -X DELETE http://localhost:8080/api/v1/imports/<uuid>
+// откат импорта: удаляет заявки в статусе NEW без писем, CRM и заметок, и кандидатов без других заявок;
+// остальные заявки перечислены в kept; суточный импорт заявок с формы (webhook) не откатывается
+func (h *Handler) RevertImport(ctx *gin.Context) {
+	importID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid import_id"})
+		return
+	}
+
+	res, err := h.service.RevertImport(ctx.Request.Context(), importID, currentUser(ctx))
 	if err != nil {
-		h.logger.Error("h.service.ProcessXLSX: ", zap.Error(err))
 		switch {
-		case errors.Is(err, custom_errors.ErrFailedToOpenFile):
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "невозможно открыть загруженнный xlsx"})
-		case errors.Is(err, custom_errors.ErrFailedToReadFile):
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "невозможно прочесть файл"})
-		case errors.Is(err, custom_errors.ErrInvalidXLSX):
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "невалидный xlsx"})
-		case errors.Is(err, custom_errors.ErrNoXLSXSheets):
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "xlsx не имеет страниц"})
-		case errors.Is(err, custom_errors.ErrNoXLSXData):
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "xlsx не имеет данных"})
+		case services.IsImportNotFound(err):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "импорт не найден"})
+		case services.IsImportNotRevertible(err):
+			ctx.JSON(http.StatusConflict, gin.H{"error": "откатить можно только завершённый импорт (PARSED или PARTIAL)"})
+		case services.IsIntakeImportNotRevertible(err):
+			ctx.JSON(http.StatusConflict, gin.H{"error": "импорт заявок с формы не откатывается, в него продолжают поступать заявки"})
+		case services.IsRevertCandidatesLocked(err):
+			ctx.JSON(http.StatusConflict, gin.H{"error": "кандидатов импорта сейчас обрабатывает другой импорт, повторите откат позже"})
 		default:
+			h.logger.Error("h.service.RevertImport: ", zap.Error(err))
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
 		}
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"import_id":     procResult.ImportId,
-		"file_sha256":   procResult.FileSha256,
-		"total_rows":    procResult.TotalRows,
-		"inserted_rows": procResult.InsertedRows,
-		"skipped_rows":  procResult.SkippedRows,
-		"errors":        procResult.Errors,
+	ctx.JSON(http.StatusOK, res)
+}
+
+// curl -o errors.xlsx http://localhost:8080/api/v1/imports/<uuid>/errors.xlsx
+// отклонённые строки в исходном виде со столбцом "Ошибка" — исправить и загрузить заново
+func (h *Handler) DownloadImportErrors(ctx *gin.Context) {
+	importID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid import_id"})
+		return
+	}
+
+	name, buf, err := h.service.ImportErrorsXLSX(ctx.Request.Context(), importID)
+	if err != nil {
+		if services.IsImportNotFound(err) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "импорт не найден"})
+			return
+		}
+		h.logger.Error("h.service.ImportErrorsXLSX: ", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+		return
+	}
+
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	ctx.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", buf.Bytes())
+}
+
+// curl -X POST http://localhost:8080/api/v1/imports/<uuid>/events/token -H "Authorization: Bearer <jwt>"
+// токен для EventSource, который не умеет заголовки: new EventSource(".../events?token=" + token).
+// Живёт streamTokenTTL и годится только для событий этого импорта; после обрыва связи нужен новый
+func (h *Handler) IssueImportEventsToken(ctx *gin.Context) {
+	importID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid import_id"})
+		return
+	}
+
+	if _, err = h.service.GetImportProgress(ctx.Request.Context(), importID); err != nil {
+		if services.IsImportNotFound(err) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "импорт не найден"})
+			return
+		}
+		h.logger.Error("h.service.GetImportProgress: ", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+		return
+	}
+
+	token, exp, err := signStreamToken(h.cfg.JWTKey, currentUser(ctx), importID.String(), time.Now())
+	if err != nil {
+		h.logger.Error("signStreamToken: ", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"token": token, "expires_at": exp.UTC()})
+}
+
+// curl -N http://localhost:8080/api/v1/imports/<uuid>/events -H "Authorization: Bearer <jwt>"
+// curl -N "http://localhost:8080/api/v1/imports/<uuid>/events?token=<токен из events/token>"
+// SSE: событие progress раз в секунду, пока импорт не завершится, затем done
+func (h *Handler) StreamImportEvents(ctx *gin.Context) {
+	importID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid import_id"})
+		return
+	}
+
+	reqCtx := ctx.Request.Context()
+	progress, err := h.service.GetImportProgress(reqCtx, importID)
+	if err != nil {
+		if services.IsImportNotFound(err) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "импорт не найден"})
+			return
+		}
+		h.logger.Error("h.service.GetImportProgress: ", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("X-Accel-Buffering", "no")
+
+	ticker := time.NewTicker(importEventsInterval)
+	defer ticker.Stop()
+
+	ctx.Stream(func(w io.Writer) bool {
+		if progress.Done() {
+			ctx.SSEvent("done", progress)
+			return false
+		}
+		ctx.SSEvent("progress", progress)
+
+		select {
+		case <-reqCtx.Done():
+			return false
+		case <-ticker.C:
+		}
+
+		progress, err = h.service.GetImportProgress(reqCtx, importID)
+		if err != nil {
+			if reqCtx.Err() == nil {
+				h.logger.Error("h.service.GetImportProgress: ", zap.Error(err))
+				ctx.SSEvent("error", gin.H{"error": "внутренняя ошибка сервера"})
+			}
+			return false
+		}
+		return true
 	})
 }
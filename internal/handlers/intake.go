@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/services"
+	"go.uber.org/zap"
+)
+
+const (
+	intakeMaxBody         = 64 << 10
+	intakeSignatureHeader = "X-Signature"
+	intakeSignaturePrefix = "sha256="
+	intakeTimestampHeader = "X-Timestamp"
+	// насколько время подписи может расходиться с нашим: старую подпись не повторить
+	intakeMaxSkew = 5 * time.Minute
+)
+
+// body='{"last_name":"Петров","first_name":"Пётр","email":"petrov@example.ru","priority1":"Аналитика","applied_at":"2025-10-09T12:00:00+03:00"}'
+// ts=$(date +%s)
+// sig=$(printf '%s.%s' "$ts" "$body" | openssl dgst -sha256 -hmac "$INTAKE_SECRET" | sed 's/^.* //')
+// curl -X POST http://localhost:8080/api/v1/applications/intake -H "X-Timestamp: $ts" -H "X-Signature: sha256=$sig" -d "$body"
+// заявка с формы; поля — как в ParsedRow, подпись — HMAC-SHA256 строки "<unix время>.<тело>" общим ключом.
+// Без applied_at временем подачи считается X-Timestamp, он входит в подпись. Поэтому повтор того же
+// запроса в пределах intakeMaxSkew даёт тот же external_key и не создаёт дубль, а возвращает 200 duplicate.
+// Разные запросы с одной заявкой без applied_at — разные заявки: форме лучше передавать applied_at.
+func (h *Handler) IntakeApplication(ctx *gin.Context) {
+	if h.cfg.IntakeSecret == "" {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "приём заявок не настроен"})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, intakeMaxBody+1))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "не удалось прочитать тело запроса"})
+		return
+	}
+	if len(body) > intakeMaxBody {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "заявка слишком большая"})
+		return
+	}
+	ts := ctx.GetHeader(intakeTimestampHeader)
+	signedAt, ok := freshTimestamp(ts, time.Now())
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "нет или устарел " + intakeTimestampHeader})
+		return
+	}
+	if !validSignature(h.cfg.IntakeSecret, ts, body, ctx.GetHeader(intakeSignatureHeader)) {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "неверная подпись"})
+		return
+	}
+
+	var fields map[string]any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber() // год рождения и т.п. без преобразования во float
+	if err = dec.Decode(&fields); err != nil || fields == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ожидается JSON-объект с полями заявки"})
+		return
+	}
+
+	res, err := h.service.IntakeApplication(ctx.Request.Context(), fields, signedAt)
+	if err != nil {
+		var rejected *services.IntakeRejectedError
+		if errors.As(err, &rejected) {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": "заявка не прошла проверку", "errors": rejected.Errors})
+			return
+		}
+		h.logger.Error("h.service.IntakeApplication: ", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+		return
+	}
+
+	if res.Status == models.IntakeDuplicate {
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+	ctx.JSON(http.StatusCreated, res)
+}
+
+// freshTimestamp - unix время в секундах, не дальше intakeMaxSkew от now
+func freshTimestamp(ts string, now time.Time) (time.Time, bool) {
+	sec, err := strconv.ParseInt(strings.TrimSpace(ts), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	signedAt := time.Unix(sec, 0)
+	skew := now.Sub(signedAt)
+	return signedAt, skew <= intakeMaxSkew && skew >= -intakeMaxSkew
+}
+
+// validSignature - "sha256=<hex>" от HMAC-SHA256 "<ts>.<тело>"; сравнение за постоянное время
+func validSignature(secret, ts string, body []byte, header string) bool {
+	sig, ok := strings.CutPrefix(strings.TrimSpace(header), intakeSignaturePrefix)
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.TrimSpace(ts) + "."))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func sign(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "." + string(body)))
+	return intakeSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestValidSignature(t *testing.T) {
+	body := []byte(`{"email":"petrov@example.ru"}`)
+	ts := "1760000000"
+	sig := sign("secret", ts, body)
+
+	if !validSignature("secret", ts, body, sig) {
+		t.Fatal("valid signature rejected")
+	}
+	if validSignature("secret", "1760000001", body, sig) {
+		t.Error("signature accepted with another timestamp")
+	}
+	if validSignature("other", ts, body, sig) {
+		t.Error("signature accepted with another secret")
+	}
+	if validSignature("secret", ts, []byte(`{"email":"ivanov@example.ru"}`), sig) {
+		t.Error("signature accepted for another body")
+	}
+	if validSignature("secret", ts, body, sig[len(intakeSignaturePrefix):]) {
+		t.Error("signature without prefix accepted")
+	}
+}
+
+func TestFreshTimestamp(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	cases := []struct {
+		ts   string
+		want bool
+	}{
+		{strconv.FormatInt(now.Unix(), 10), true},
+		{strconv.FormatInt(now.Add(-4*time.Minute).Unix(), 10), true},
+		{strconv.FormatInt(now.Add(4*time.Minute).Unix(), 10), true},
+		{strconv.FormatInt(now.Add(-6*time.Minute).Unix(), 10), false},
+		{strconv.FormatInt(now.Add(6*time.Minute).Unix(), 10), false},
+		{"", false},
+		{"2025-10-09T12:00:00Z", false},
+	}
+	for _, c := range cases {
+		if _, got := freshTimestamp(c.ts, now); got != c.want {
+			t.Errorf("freshTimestamp(%q) = %v, want %v", c.ts, got, c.want)
+		}
+	}
+}
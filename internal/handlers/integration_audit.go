@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"go.uber.org/zap"
+)
+
+// curl "http://localhost:8080/api/v1/integration-audit?system=crm&candidate_id=<uuid>&from=2025-01-01"
+func (h *Handler) ListIntegrationAudit(ctx *gin.Context) {
+	p := models.ListIntegrationAuditParams{
+		Limit:  parseInt(ctx.Query("limit"), 50),
+		Offset: parseInt(ctx.Query("offset"), 0),
+
+		System:      strings.TrimSpace(ctx.Query("system")),
+		EntityID:    strings.TrimSpace(ctx.Query("entity_id")),
+		CandidateID: strings.TrimSpace(ctx.Query("candidate_id")),
+	}
+
+	if p.EntityID != "" {
+		if _, err := uuid.Parse(p.EntityID); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid entity_id"})
+			return
+		}
+	}
+	if p.CandidateID != "" {
+		if _, err := uuid.Parse(p.CandidateID); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid candidate_id"})
+			return
+		}
+	}
+
+	// from / to: RFC3339 или YYYY-MM-DD
+	if v := strings.TrimSpace(ctx.Query("from")); v != "" {
+		t, err := parseTime(v)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+			return
+		}
+		p.From = &t
+	}
+	if v := strings.TrimSpace(ctx.Query("to")); v != "" {
+		t, err := parseTime(v)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+			return
+		}
+		p.To = &t
+	}
+
+	res, err := h.service.ListIntegrationAudit(ctx.Request.Context(), p)
+	if err != nil {
+		h.logger.Error("h.service.ListIntegrationAudit: ", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
@@ -1,19 +1,40 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/services"
+	"github.com/kurushqosimi/x5-intern-hiring/pkg/jwt"
 	"go.uber.org/zap"
 )
 
+const (
+	apiKeyHeader  = "X-API-Key"
+	userIDKey     = "user_id"
+	authMethodKey = "auth_method"
+
+	// EventSource не умеет заголовки: SSE импорта принимает короткий токен в query
+	streamTokenParam    = "token"
+	streamTokenTTL      = time.Minute
+	streamTokenAudience = "import-events:"
+
+	// чем запрос подтвердил пользователя, в контексте под authMethodKey
+	authMethodJWT    = "jwt"
+	authMethodAPIKey = "api_key"
+)
+
 func ZapLogger(l *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
-		raw := c.Request.URL.RawQuery
+		raw := redactQuery(c.Request.URL)
 
 		c.Next()
 
@@ -46,6 +67,16 @@ func ZapLogger(l *zap.Logger) gin.HandlerFunc {
 	}
 }
 
+// redactQuery - query для лога без токена SSE
+func redactQuery(u *url.URL) string {
+	q := u.Query()
+	if !q.Has(streamTokenParam) {
+		return u.RawQuery
+	}
+	q.Set(streamTokenParam, "***")
+	return q.Encode()
+}
+
 func Recovery(l *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
@@ -86,7 +117,7 @@ func CORS(allowedOrigins []string) gin.HandlerFunc {
 
 		c.Writer.Header().Set("Vary", "Origin")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-Id")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-Id, X-API-Key")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 
 		if c.Request.Method == http.MethodOptions {
@@ -96,3 +127,134 @@ func CORS(allowedOrigins []string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// Auth - запрос должен нести JWT (Authorization: Bearer <token>) или API ключ
+// (X-API-Key: <key> или Authorization: Bearer x5_...). Пользователь кладётся в контекст.
+func Auth(l *zap.Logger, service *services.Service, jwtKey []byte) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := strings.TrimSpace(c.GetHeader(apiKeyHeader))
+		if token == "" {
+			if v, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+				token = strings.TrimSpace(v)
+			}
+		}
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "требуется авторизация"})
+			return
+		}
+
+		userID, method, err := authenticate(c.Request.Context(), service, jwtKey, token)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidCredentials) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "недействительный токен или ключ"})
+				return
+			}
+			l.Error("authenticate: ", zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+			return
+		}
+
+		c.Set(userIDKey, userID)
+		c.Set(authMethodKey, method)
+		c.Next()
+	}
+}
+
+// RequireJWT - после Auth: маршрут только для входа по JWT. Утёкшим API ключом
+// нельзя выпустить новый ключ или отозвать остальные ключи владельца.
+func RequireJWT() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString(authMethodKey) != authMethodJWT {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "доступно только по JWT, не по API ключу"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// authenticate - пользователь и способ входа: authMethodAPIKey или authMethodJWT
+func authenticate(ctx context.Context, service *services.Service, jwtKey []byte, token string) (uuid.UUID, string, error) {
+	if services.IsAPIKey(token) {
+		userID, err := service.AuthenticateAPIKey(ctx, token)
+		return userID, authMethodAPIKey, err
+	}
+
+	claims, err := jwt.Parse(token, jwtKey, time.Now())
+	// токен с audience (токен SSE) годится только для своего маршрута
+	if err != nil || claims.Audience != "" {
+		return uuid.Nil, "", services.ErrInvalidCredentials
+	}
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return uuid.Nil, "", services.ErrInvalidCredentials
+	}
+	if err = service.AuthenticateUser(ctx, userID); err != nil {
+		return uuid.Nil, "", err
+	}
+	return userID, authMethodJWT, nil
+}
+
+// StreamAuth - Auth для SSE импорта: вместо заголовка можно передать ?token= из IssueImportEventsToken.
+// Такой токен живёт streamTokenTTL и подходит только к событиям своего импорта.
+func StreamAuth(l *zap.Logger, service *services.Service, jwtKey []byte) gin.HandlerFunc {
+	auth := Auth(l, service, jwtKey)
+	return func(c *gin.Context) {
+		token := c.Query(streamTokenParam)
+		if token == "" {
+			auth(c)
+			return
+		}
+
+		userID, err := authenticateStream(c.Request.Context(), service, jwtKey, token, c.Param("id"))
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidCredentials) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "недействительный токен или ключ"})
+				return
+			}
+			l.Error("authenticateStream: ", zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "внутренняя ошибка сервера"})
+			return
+		}
+
+		c.Set(userIDKey, userID)
+		c.Set(authMethodKey, authMethodJWT)
+		c.Next()
+	}
+}
+
+func authenticateStream(ctx context.Context, service *services.Service, jwtKey []byte, token, importID string) (uuid.UUID, error) {
+	claims, err := jwt.Parse(token, jwtKey, time.Now())
+	if err != nil || claims.Audience != streamTokenAudience+importID {
+		return uuid.Nil, services.ErrInvalidCredentials
+	}
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return uuid.Nil, services.ErrInvalidCredentials
+	}
+	if err = service.AuthenticateUser(ctx, userID); err != nil {
+		return uuid.Nil, err
+	}
+	return userID, nil
+}
+
+// signStreamToken - токен для ?token= у событий импорта importID
+func signStreamToken(jwtKey []byte, userID uuid.UUID, importID string, now time.Time) (string, time.Time, error) {
+	exp := now.Add(streamTokenTTL)
+	token, err := jwt.Sign(jwt.Claims{
+		Subject:   userID.String(),
+		Audience:  streamTokenAudience + importID,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: exp.Unix(),
+	}, jwtKey)
+	return token, exp, err
+}
+
+// currentUser - кто выполняет запрос (после Auth); uuid.Nil — не известно
+func currentUser(c *gin.Context) uuid.UUID {
+	if v, ok := c.Get(userIDKey); ok {
+		if id, ok := v.(uuid.UUID); ok {
+			return id
+		}
+	}
+	return uuid.Nil
+}
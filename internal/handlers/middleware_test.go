@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/services"
+	"github.com/kurushqosimi/x5-intern-hiring/pkg/jwt"
+	"go.uber.org/zap"
+)
+
+func TestRequireJWT(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		method string
+		want   int
+	}{
+		{authMethodJWT, http.StatusOK},
+		{authMethodAPIKey, http.StatusForbidden},
+		{"", http.StatusForbidden},
+	}
+	for _, c := range cases {
+		r := gin.New()
+		r.GET("/api-keys", func(ctx *gin.Context) {
+			if c.method != "" {
+				ctx.Set(authMethodKey, c.method)
+			}
+			ctx.Next()
+		}, RequireJWT(), func(ctx *gin.Context) {
+			ctx.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api-keys", nil))
+		if w.Code != c.want {
+			t.Errorf("auth method %q: status = %d, want %d", c.method, w.Code, c.want)
+		}
+	}
+}
+
+func TestStreamAuthRejects(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	key := []byte("test-key")
+	importID, other := uuid.NewString(), uuid.NewString()
+	userID := uuid.New()
+	now := time.Now()
+
+	otherImport, _, err := signStreamToken(key, userID, other, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, _, err := signStreamToken(key, userID, importID, now.Add(-2*streamTokenTTL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	login, err := jwt.Sign(jwt.Claims{Subject: userID.String(), ExpiresAt: now.Add(time.Hour).Unix()}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// до сервиса эти токены не доходят, поэтому он не нужен
+	r := gin.New()
+	r.GET("/imports/:id/events", StreamAuth(zap.NewNop(), nil, key), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	for name, token := range map[string]string{
+		"другой импорт": otherImport,
+		"истёк":         expired,
+		"токен входа":   login,
+		"мусор":         "abc",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/imports/"+importID+"/events?token="+token, nil))
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: status = %d, want 401", name, w.Code)
+		}
+	}
+
+	// и наоборот: токен SSE не заменяет токен входа в заголовке
+	stream, _, err := signStreamToken(key, userID, importID, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = authenticate(context.Background(), nil, key, stream); !errors.Is(err, services.ErrInvalidCredentials) {
+		t.Errorf("stream token as bearer: err = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestRedactQuery(t *testing.T) {
+	u, _ := url.Parse("/imports/1/events?token=secret&x=1")
+	if got := redactQuery(u); strings.Contains(got, "secret") {
+		t.Errorf("redactQuery = %q, token left in log", got)
+	}
+	u, _ = url.Parse("/imports?limit=10&offset=20")
+	if got := redactQuery(u); got != "limit=10&offset=20" {
+		t.Errorf("redactQuery = %q, want raw query", got)
+	}
+}
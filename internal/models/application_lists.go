@@ -21,8 +21,9 @@ type ListApplicationsParams struct {
 	AppliedFrom *time.Time
 	AppliedTo   *time.Time
 
-	HasResume *bool
-	ImportID  string // optional (uuid as string)
+	HasResume       *bool
+	ImportID        string // optional (uuid as string)
+	StatusChangedBy string // optional (uuid as string)
 }
 
 type ApplicationListItem struct {
@@ -38,9 +39,10 @@ type ApplicationListItem struct {
 	Citizenship string `json:"citizenship,omitempty"`
 	Languages   string `json:"languages,omitempty"`
 
-	Email    string `json:"email,omitempty"`
-	Phone    string `json:"phone,omitempty"`
-	Telegram string `json:"telegram,omitempty"`
+	Email        string `json:"email,omitempty"`
+	EmailInvalid bool   `json:"email_invalid,omitempty"` // у кандидата нет ни одного валидного email
+	Phone        string `json:"phone,omitempty"`
+	Telegram     string `json:"telegram,omitempty"`
 
 	ResumeURL string `json:"resume_url,omitempty"`
 
@@ -61,7 +63,8 @@ type ApplicationListItem struct {
 
 	Source string `json:"source,omitempty"`
 
-	StatusReason string `json:"status_reason,omitempty"`
+	StatusReason    string `json:"status_reason,omitempty"`
+	StatusChangedBy string `json:"status_changed_by,omitempty"` // кто последним менял статус
 }
 
 type ListApplicationsResponse struct {
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+type User struct {
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// APIKey - ключ для интеграций; сам ключ отдаётся только при выпуске
+type APIKey struct {
+	KeyID      string     `json:"key_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // начало ключа, чтобы узнать его в списке
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type IssueAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // пусто — бессрочный
+}
+
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type ListAPIKeysResponse struct {
+	Items []APIKey `json:"items"`
+}
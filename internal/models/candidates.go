@@ -0,0 +1,62 @@
+package models
+
+type MergeCandidatesRequest struct {
+	SourceCandidateID string `json:"source_candidate_id"` // будет удалён
+	TargetCandidateID string `json:"target_candidate_id"` // останется
+}
+
+type MergeCandidatesResponse struct {
+	MergeID           string `json:"merge_id"`
+	TargetCandidateID string `json:"target_candidate_id"`
+	ApplicationsMoved int    `json:"applications_moved"`
+	ContactsMoved     int    `json:"contacts_moved"`
+}
+
+// причины, по которым пара кандидатов похожа на дубль
+const (
+	DuplicateReasonName        = "name"
+	DuplicateReasonBirthYear   = "birth_year"
+	DuplicateReasonEmailLocal  = "email_local_part"
+	DuplicateReasonPhoneSuffix = "phone_suffix"
+)
+
+type DuplicateCandidate struct {
+	CandidateID string   `json:"candidate_id"`
+	FirstName   string   `json:"first_name"`
+	LastName    string   `json:"last_name"`
+	BirthYear   *int     `json:"birth_year,omitempty"`
+	Contacts    []string `json:"contacts"`
+}
+
+type DuplicatePair struct {
+	A       DuplicateCandidate `json:"a"`
+	B       DuplicateCandidate `json:"b"`
+	Reasons []string           `json:"reasons"`
+	Score   int                `json:"score"`
+}
+
+type ListDuplicatesResponse struct {
+	Items  []DuplicatePair `json:"items"`
+	Limit  int             `json:"limit"`
+	Offset int             `json:"offset"`
+}
+
+// ContactBackfillIssue - контакт, который не удалось перенормализовать
+type ContactBackfillIssue struct {
+	ContactID   string `json:"contact_id"`
+	CandidateID string `json:"candidate_id"`
+	Value       string `json:"value"`
+	Normalized  string `json:"normalized,omitempty"`
+	Reason      string `json:"reason"`
+}
+
+type ContactBackfillResult struct {
+	Type      string                 `json:"type"`
+	DryRun    bool                   `json:"dry_run"`
+	Total     int                    `json:"total"`
+	Updated   int                    `json:"updated"`
+	Unchanged int                    `json:"unchanged"`
+	Removed   int                    `json:"removed"` // дубли у того же кандидата
+	Invalid   []ContactBackfillIssue `json:"invalid"`
+	Conflicts []ContactBackfillIssue `json:"conflicts"` // номер уже у другого кандидата — кандидатов нужно слить
+}
@@ -0,0 +1,18 @@
+package models
+
+import "github.com/google/uuid"
+
+// crm outbox statuses
+const (
+	CRMPending = "PENDING"
+	CRMSent    = "SENT"
+	CRMDead    = "DEAD"
+)
+
+// OutboxCRM - захваченная диспетчером запись crm_outbox
+type OutboxCRM struct {
+	CRMID         uuid.UUID
+	ApplicationID uuid.UUID
+	Payload       []byte
+	Attempt       int
+}
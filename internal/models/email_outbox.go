@@ -0,0 +1,55 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// email outbox statuses
+const (
+	EmailPending = "PENDING"
+	EmailSent    = "SENT"
+	EmailDead    = "DEAD" // попытки исчерпаны, нужен ручной requeue
+)
+
+// OutboxEmail - захваченное воркером письмо вместе с шаблоном
+type OutboxEmail struct {
+	EmailID       uuid.UUID
+	ApplicationID uuid.UUID
+	ToEmail       string
+	RenderVars    map[string]any
+	Attempt       int
+	Subject       string
+	Body          string
+}
+
+type DeadEmailItem struct {
+	EmailID       string    `json:"email_id"`
+	ApplicationID string    `json:"application_id"`
+	ToEmail       string    `json:"to_email"`
+	TemplateCode  string    `json:"template_code"`
+	FirstName     string    `json:"first_name"`
+	LastName      string    `json:"last_name"`
+	Attempt       int       `json:"attempt"`
+	LastError     string    `json:"last_error,omitempty"`
+	CreatedBy     string    `json:"created_by,omitempty"` // кто поставил письмо
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type ListDeadEmailsResponse struct {
+	Items  []DeadEmailItem `json:"items"`
+	Total  int             `json:"total"`
+	Limit  int             `json:"limit"`
+	Offset int             `json:"offset"`
+}
+
+type RequeueEmailsRequest struct {
+	EmailIDs []string `json:"email_ids"`
+	All      bool     `json:"all"` // вернуть в очередь все DEAD письма
+}
+
+type RequeueEmailsResponse struct {
+	Requeued int `json:"requeued"`
+	Skipped  int `json:"skipped"`
+}
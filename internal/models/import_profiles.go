@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+// ParsedRow fields, на которые профиль импорта отображает столбцы файла
+const (
+	FieldLastName        = "last_name"
+	FieldFirstName       = "first_name"
+	FieldEmail           = "email"
+	FieldPhone           = "phone"
+	FieldTelegram        = "telegram"
+	FieldResumeURL       = "resume_url"
+	FieldPriority1       = "priority1"
+	FieldPriority2       = "priority2"
+	FieldCourse          = "course"
+	FieldSpecialty       = "specialty"
+	FieldSpecialtyOther  = "specialty_other"
+	FieldSchedule        = "schedule"
+	FieldCity            = "city"
+	FieldCityOther       = "city_other"
+	FieldSource          = "source"
+	FieldBirthYear       = "birth_year"
+	FieldCitizenship     = "citizenship"
+	FieldUniversity      = "university"
+	FieldUniversityOther = "university_other"
+	FieldLanguages       = "languages"
+	FieldAppliedAt       = "applied_at"
+)
+
+var ImportFields = []string{
+	FieldLastName, FieldFirstName, FieldEmail, FieldPhone, FieldTelegram, FieldResumeURL,
+	FieldPriority1, FieldPriority2, FieldCourse, FieldSpecialty, FieldSpecialtyOther, FieldSchedule,
+	FieldCity, FieldCityOther, FieldSource, FieldBirthYear, FieldCitizenship,
+	FieldUniversity, FieldUniversityOther, FieldLanguages, FieldAppliedAt,
+}
+
+const DefaultImportProfileCode = "default"
+
+// ColumnMapping - какие заголовки файла считаются данным полем
+type ColumnMapping struct {
+	Aliases  []string `json:"aliases"`
+	Required bool     `json:"required,omitempty"`
+}
+
+type ImportProfile struct {
+	ProfileID string                   `json:"profile_id,omitempty"`
+	Code      string                   `json:"code"`
+	Name      string                   `json:"name"`
+	Columns   map[string]ColumnMapping `json:"columns"`
+	// DateLayouts - дополнительные форматы даты в нотации Go ("02/01/2006 15:04"),
+	// проверяются раньше встроенных
+	DateLayouts []string   `json:"date_layouts,omitempty"`
+	IsDefault   bool       `json:"is_default"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+// DefaultImportProfile - встроенный профиль под выгрузку формы откликов.
+// Используется, если в БД нет профиля с is_default=true.
+func DefaultImportProfile() ImportProfile {
+	return ImportProfile{
+		Code: DefaultImportProfileCode,
+		Name: "Выгрузка формы откликов",
+		Columns: map[string]ColumnMapping{
+			FieldLastName:        {Aliases: []string{"Фамилия"}, Required: true},
+			FieldFirstName:       {Aliases: []string{"Имя"}, Required: true},
+			FieldTelegram:        {Aliases: []string{"ТГ", "Telegram", "Телеграм"}},
+			FieldPhone:           {Aliases: []string{"Телефон"}},
+			FieldEmail:           {Aliases: []string{"Почта", "Email", "E-mail"}},
+			FieldResumeURL:       {Aliases: []string{"Резюме"}},
+			FieldPriority1:       {Aliases: []string{"Первый приоритет"}},
+			FieldPriority2:       {Aliases: []string{"Второй приоритет"}},
+			FieldCourse:          {Aliases: []string{"Курс"}},
+			FieldSpecialty:       {Aliases: []string{"Специальность"}},
+			FieldSpecialtyOther:  {Aliases: []string{"Другая специальность"}},
+			FieldSchedule:        {Aliases: []string{"График"}},
+			FieldCity:            {Aliases: []string{"Город"}},
+			FieldCityOther:       {Aliases: []string{"Другой город"}},
+			FieldSource:          {Aliases: []string{"Откуда узнал"}},
+			FieldBirthYear:       {Aliases: []string{"Год рождения"}},
+			FieldCitizenship:     {Aliases: []string{"Гражданство"}},
+			FieldUniversity:      {Aliases: []string{"ВУЗ"}},
+			FieldUniversityOther: {Aliases: []string{"Другой ВУЗ"}},
+			FieldLanguages:       {Aliases: []string{"Языки"}},
+			FieldAppliedAt:       {Aliases: []string{"Дата заявки"}, Required: true},
+		},
+	}
+}
+
+type ListImportProfilesResponse struct {
+	Items []ImportProfile `json:"items"`
+}
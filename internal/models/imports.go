@@ -1,74 +1,282 @@
 package models
 
 import (
+	"fmt"
 	"github.com/google/uuid"
 	"time"
 )
 
 // file statuses
 const (
-	FileCreated = "CREATED"
-	FileFailed  = "FAILED"
-	FileParsed  = "PARSED"
+	FileCreated    = "CREATED"
+	FileQueued     = "QUEUED"
+	FileProcessing = "PROCESSING"
+	FileFailed     = "FAILED"
+	FileParsed     = "PARSED"
+	FilePartial    = "PARTIAL" // часть строк отвергла БД, остальное сохранено
+	FileReverted   = "REVERTED"
 )
 
-// file columns' names
+// file formats
 const (
-	LastName             = "Фамилия"
-	FirstName            = "Имя"
-	Telegram             = "ТГ"
-	Cellphone            = "Телефон"
-	Email                = "Почта"
-	ResumeURL            = "Резюме"
-	FirstPriority        = "Первый приоритет"
-	SecondPriority       = "Второй приоритет"
-	Course               = "Курс"
-	Speciality           = "Специальность"
-	OtherSpeciality      = "Другая специальность"
-	Schedule             = "График"
-	City                 = "Город"
-	OtherCity            = "Другой город"
-	Source               = "Откуда узнал"
-	YearBorn             = "Год рождения"
-	Citizenship          = "Гражданство"
-	University           = "ВУЗ"
-	OtherUniversity      = "Другой ВУЗ"
-	ProgrammingLanguages = "Языки"
-	ApplicationDate      = "Дата заявки"
+	FormatXLSX = "xlsx"
+	FormatCSV  = "csv" // разделитель определяется по содержимому
+	FormatTSV  = "tsv"
 )
 
 type ParsedRow struct {
-	LastName        string
-	FirstName       string
-	Email           string
-	Phone           string
-	Telegram        string
-	ResumeURL       string
-	Priority1       string
-	Priority2       string
-	Course          string
-	Specialty       string
-	SpecialtyOther  string
-	Schedule        string
-	City            string
-	CityOther       string
-	Source          string
-	BirthYear       *int
-	Citizenship     string
-	University      string
-	UniversityOther string
-	Languages       string
-	AppliedAt       time.Time
-	RawRow          map[string]any
+	LastName        string         `json:"last_name"`
+	FirstName       string         `json:"first_name"`
+	Email           string         `json:"email,omitempty"`
+	EmailNormalized string         `json:"email_normalized,omitempty"`
+	EmailInvalid    string         `json:"email_invalid,omitempty"`    // причина, если адрес не прошёл проверку
+	Phone           string         `json:"phone,omitempty"`            // как в файле, для отображения
+	PhoneNormalized string         `json:"phone_normalized,omitempty"` // E.164
+	Telegram        string         `json:"telegram,omitempty"`
+	ResumeURL       string         `json:"resume_url,omitempty"`
+	Priority1       string         `json:"priority1,omitempty"`
+	Priority2       string         `json:"priority2,omitempty"`
+	Course          string         `json:"course,omitempty"`
+	Specialty       string         `json:"specialty,omitempty"`
+	SpecialtyOther  string         `json:"specialty_other,omitempty"`
+	Schedule        string         `json:"schedule,omitempty"`
+	City            string         `json:"city,omitempty"`
+	CityOther       string         `json:"city_other,omitempty"`
+	Source          string         `json:"source,omitempty"`
+	BirthYear       *int           `json:"birth_year,omitempty"`
+	Citizenship     string         `json:"citizenship,omitempty"`
+	University      string         `json:"university,omitempty"`
+	UniversityOther string         `json:"university_other,omitempty"`
+	Languages       string         `json:"languages,omitempty"`
+	AppliedAt       time.Time      `json:"applied_at"`
+	RawRow          map[string]any `json:"-"`
+	Sheet           string         `json:"sheet,omitempty"` // лист книги xlsx, из которого взята строка
+	Row             int            `json:"-"`               // номер строки на листе
+	Cells           []string       `json:"-"`               // исходные ячейки, для отчёта об ошибках
 }
 
 type FileMetaData struct {
 	ImportID     uuid.UUID
 	UploadedBy   uuid.UUID
+	ProfileCode  string
 	FileName     string
 	FileSha256   string
+	Format       string
 	Status       string
 	TotalRows    int
 	InsertedRows int
 	SkippedRows  int
+	Forced       bool   // повторный импорт того же файла по force
+	Owner        string // процесс, который ведёт импорт: ImportOwnerAPI, ImportOwnerWatch
+}
+
+// процессы, которые ведут импорт: незавершённые импорты каждый при старте помечает упавшими сам
+const (
+	ImportOwnerAPI   = "api"
+	ImportOwnerWatch = "import-watch"
+)
+
+// InsertStats - итог вставки строк. Кандидаты считаются по вставленным заявкам:
+// найденный по контакту кандидат — matched, новый — created.
+type InsertStats struct {
+	Inserted          int `json:"inserted_rows"`
+	Skipped           int `json:"skipped_rows"`
+	Failed            int `json:"failed_rows"` // отвергнуты БД, в импорт не вошли
+	CandidatesCreated int `json:"candidates_created"`
+	CandidatesMatched int `json:"candidates_matched"`
+	CandidatesUpdated int `json:"candidates_updated"` // из matched — у кого изменился профиль
+
+	BySheet map[string]SheetInsertStats `json:"-"` // для xlsx: вставлено/пропущено по листам
+}
+
+type SheetInsertStats struct {
+	Inserted int
+	Skipped  int
+	Failed   int
+}
+
+// SheetStats - итог импорта по одному листу книги
+type SheetStats struct {
+	Sheet        string `json:"sheet"`
+	TotalRows    int    `json:"total_rows"` // строк, прошедших разбор
+	InsertedRows int    `json:"inserted_rows"`
+	SkippedRows  int    `json:"skipped_rows"`
+	FailedRows   int    `json:"failed_rows"`
+	ErrorsCount  int    `json:"errors_count"`
+}
+
+// SheetInfo - лист загруженной книги и подходит ли его заголовок под профиль
+type SheetInfo struct {
+	Index          int      `json:"index"`
+	Name           string   `json:"name"`
+	Rows           int      `json:"rows"` // непустых строк без заголовка
+	Header         []string `json:"header"`
+	Matches        bool     `json:"matches"`
+	MissingColumns []string `json:"missing_columns,omitempty"`
+}
+
+type ListSheetsResponse struct {
+	FileName string      `json:"file_name"`
+	Profile  string      `json:"profile"`
+	Sheets   []SheetInfo `json:"sheets"`
+}
+
+// ImportOptions - параметры загрузки файла
+type ImportOptions struct {
+	Force   bool   // импортировать, даже если такой файл уже загружался
+	DryRun  bool   // только показать результат, ничего не сохранять
+	Profile string // код профиля импорта, пусто — профиль по умолчанию
+	// листы xlsx: пусто — первый лист; AllSheets — все листы с подходящим заголовком
+	Sheets    []string
+	AllSheets bool
+	Format    string // формат файла, пусто — по расширению и содержимому
+	// UploadedBy - кто загрузил файл; uuid.Nil — не известно (import-watch, старые клиенты)
+	UploadedBy uuid.UUID
+}
+
+// DryRunResult - что произойдёт при импорте файла, без записи в БД
+type DryRunResult struct {
+	FileName          string           `json:"file_name"`
+	FileSha256        string           `json:"file_sha256"`
+	TotalRows         int              `json:"total_rows"`   // строк, прошедших разбор
+	WouldInsert       int              `json:"would_insert"` // новых заявок
+	Duplicates        int              `json:"duplicates"`   // уже есть в БД или повторяются в файле (external_key)
+	Failed            int              `json:"failed"`       // строк, которые отвергла бы БД
+	CandidatesCreated int              `json:"candidates_created"`
+	CandidatesMatched int              `json:"candidates_matched"`
+	CandidatesUpdated int              `json:"candidates_updated"`
+	Rejected          int              `json:"rejected"` // строк с ошибками разбора
+	Errors            []ImportRowError `json:"errors"`
+	Sheets            []SheetStats     `json:"sheets,omitempty"`
+	Sample            []ParsedRow      `json:"sample"`
+	AlreadyImported   *ImportProgress  `json:"already_imported,omitempty"`
+}
+
+// коды ошибок разбора строк
+const (
+	RowErrEmptyName        = "empty_name"
+	RowErrNoContacts       = "no_contacts"
+	RowErrInvalidEmail     = "invalid_email"
+	RowErrInvalidPhone     = "invalid_phone"
+	RowErrInvalidAppliedAt = "invalid_applied_at"
+	RowErrInvalidBirthYear = "invalid_birth_year"
+	RowErrDB               = "db_error" // строка разобрана, но БД её не приняла
+)
+
+// ImportRowError - строка файла, которую не удалось разобрать.
+// Rejected=false — предупреждение: строка импортирована, но значение столбца отброшено.
+type ImportRowError struct {
+	Sheet    string `json:"sheet,omitempty"`
+	Row      int    `json:"row"`              // номер строки на листе, начиная с 1 (заголовок — строка 1)
+	Column   string `json:"column,omitempty"` // заголовок столбца в файле
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	Rejected bool   `json:"rejected"`
+	// Cells - исходные значения отклонённой строки, для отчёта об ошибках
+	Cells []string `json:"-"`
+}
+
+func (e ImportRowError) String() string {
+	if e.Sheet != "" {
+		return fmt.Sprintf("лист %q, строка %d: %s", e.Sheet, e.Row, e.Message)
+	}
+	return fmt.Sprintf("строка %d: %s", e.Row, e.Message)
+}
+
+// ImportErrorReport - отклонённые строки импорта с исходными значениями
+type ImportErrorReport struct {
+	FileName    string
+	ProfileCode string
+	Headers     map[string][]string // заголовки листов, для csv ключ ""
+	Date1904    bool                // система дат книги Excel 1904
+	Rows        []ImportRowError    // только Rejected, по листу и номеру строки
+}
+
+// ImportProgress - состояние импорта для опроса и SSE
+type ImportProgress struct {
+	ImportID          string       `json:"import_id"`
+	UploadedBy        string       `json:"uploaded_by,omitempty"`
+	FileName          string       `json:"file_name"`
+	FileSha256        string       `json:"file_sha256"`
+	Status            string       `json:"status"`
+	TotalRows         int          `json:"total_rows"`
+	ProcessedRows     int          `json:"processed_rows"`
+	InsertedRows      int          `json:"inserted_rows"`
+	SkippedRows       int          `json:"skipped_rows"`
+	FailedRows        int          `json:"failed_rows"`
+	CandidatesCreated int          `json:"candidates_created"`
+	CandidatesMatched int          `json:"candidates_matched"`
+	CandidatesUpdated int          `json:"candidates_updated"`
+	ErrorsCount       int          `json:"errors_count"`
+	Error             string       `json:"error,omitempty"`
+	CreatedAt         time.Time    `json:"created_at"`
+	StartedAt         *time.Time   `json:"started_at,omitempty"`
+	FinishedAt        *time.Time   `json:"finished_at,omitempty"`
+	Sheets            []SheetStats `json:"sheets,omitempty"`
+	RevertedBy        string       `json:"reverted_by,omitempty"`
+}
+
+// Done - импорт больше не изменится
+func (p ImportProgress) Done() bool {
+	switch p.Status {
+	case FileParsed, FilePartial, FileFailed, FileReverted:
+		return true
+	}
+	return false
+}
+
+type ImportDetails struct {
+	ImportProgress
+	Errors       []ImportRowError          `json:"errors"`
+	Applications ImportApplicationsSummary `json:"applications"`
+}
+
+// ImportApplicationsSummary - что сейчас происходит с заявками, созданными импортом
+type ImportApplicationsSummary struct {
+	Total      int            `json:"total"`
+	Candidates int            `json:"candidates"`
+	ByStatus   map[string]int `json:"by_status"`
+}
+
+type ListImportsParams struct {
+	Limit  int
+	Offset int
+
+	Statuses   []string
+	UploadedBy string // optional (uuid as string)
+
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+}
+
+type ListImportsResponse struct {
+	Items  []ImportProgress `json:"items"`
+	Total  int              `json:"total"`
+	Limit  int              `json:"limit"`
+	Offset int              `json:"offset"`
+}
+
+// причины, по которым откат импорта оставил заявку
+const (
+	RevertKeptNotNew = "status_not_new" // заявку уже обработали
+	RevertKeptEmails = "has_emails"     // по заявке ставились письма
+	RevertKeptCRM    = "has_crm"        // заявка уходила в CRM
+	RevertKeptNotes  = "has_notes"      // к заявке писали заметки
+)
+
+// RevertKeptApplication - заявка импорта, которую откат не удалил
+type RevertKeptApplication struct {
+	ApplicationID string   `json:"application_id"`
+	CandidateID   string   `json:"candidate_id"`
+	Status        string   `json:"status"`
+	Reasons       []string `json:"reasons"`
+}
+
+type RevertImportResponse struct {
+	ImportID            string                  `json:"import_id"`
+	Status              string                  `json:"status"`
+	ApplicationsDeleted int                     `json:"applications_deleted"`
+	CandidatesDeleted   int                     `json:"candidates_deleted"`
+	ContactsDeleted     int                     `json:"contacts_deleted"`
+	Kept                []RevertKeptApplication `json:"kept"`
 }
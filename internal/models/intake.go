@@ -0,0 +1,33 @@
+package models
+
+// IntakeProfileCode - профиль заявок, пришедших с формы через /applications/intake
+const IntakeProfileCode = "webhook"
+
+// результат приёма заявки с формы
+const (
+	IntakeCreated   = "created"
+	IntakeDuplicate = "duplicate" // такая заявка уже есть (external_key)
+)
+
+// IntakeProfile - заявка с формы приходит JSON-объектом, ключи которого — поля ParsedRow
+// (last_name, email, applied_at, ...); разбор тот же, что у строки файла.
+func IntakeProfile() ImportProfile {
+	columns := make(map[string]ColumnMapping, len(ImportFields))
+	for _, f := range ImportFields {
+		columns[f] = ColumnMapping{Aliases: []string{f}}
+	}
+	return ImportProfile{
+		Code:    IntakeProfileCode,
+		Name:    "Заявки с формы (webhook)",
+		Columns: columns,
+		// RFC 3339 есть среди встроенных; форма может прислать и время без зоны (московское)
+		DateLayouts: []string{"2006-01-02T15:04:05"},
+	}
+}
+
+type IntakeResponse struct {
+	ImportID         string           `json:"import_id"`
+	Status           string           `json:"status"`
+	CandidateMatched bool             `json:"candidate_matched"`
+	Warnings         []ImportRowError `json:"warnings"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// integration_audit.system
+const (
+	AuditSystemEmail = "email"
+	AuditSystemCRM   = "crm"
+)
+
+// IntegrationAudit - одна пара запрос/ответ к внешней системе
+type IntegrationAudit struct {
+	System     string
+	EntityID   uuid.UUID // application_id, к которой относится вызов
+	Request    any
+	Response   any
+	HTTPStatus *int
+}
+
+type ListIntegrationAuditParams struct {
+	Limit  int
+	Offset int
+
+	System      string
+	EntityID    string // optional (uuid as string)
+	CandidateID string // optional: все вызовы по заявкам кандидата
+
+	From *time.Time
+	To   *time.Time
+}
+
+type IntegrationAuditItem struct {
+	AuditID    string          `json:"audit_id"`
+	System     string          `json:"system"`
+	EntityID   string          `json:"entity_id"`
+	Request    json.RawMessage `json:"request,omitempty"`
+	Response   json.RawMessage `json:"response,omitempty"`
+	HTTPStatus *int            `json:"http_status,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type ListIntegrationAuditResponse struct {
+	Items  []IntegrationAuditItem `json:"items"`
+	Total  int                    `json:"total"`
+	Limit  int                    `json:"limit"`
+	Offset int                    `json:"offset"`
+}
@@ -1,12 +1,18 @@
 package models
 
 type XLSXProcRes struct {
-	ImportId     string   `json:"import_id"`
-	FileSha256   string   `json:"file_sha256"`
-	TotalRows    int      `json:"total_rows"`
-	InsertedRows int      `json:"inserted_rows"`
-	SkippedRows  int      `json:"skipped_rows"`
-	Errors       []string `json:"errors"`
+	ImportId          string           `json:"import_id"`
+	FileSha256        string           `json:"file_sha256"`
+	TotalRows         int              `json:"total_rows"`
+	InsertedRows      int              `json:"inserted_rows"`
+	SkippedRows       int              `json:"skipped_rows"`
+	FailedRows        int              `json:"failed_rows"`
+	CandidatesCreated int              `json:"candidates_created"`
+	CandidatesMatched int              `json:"candidates_matched"`
+	CandidatesUpdated int              `json:"candidates_updated"` // из matched — у кого изменился профиль
+	Errors            []ImportRowError `json:"errors"`
+
+	Sheets []SheetStats `json:"sheets,omitempty"`
 }
 
 const (
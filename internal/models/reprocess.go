@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReprocessRequest struct {
+	ImportID string `json:"import_id"`
+	All      bool   `json:"all"`   // все заявки
+	Apply    bool   `json:"apply"` // false — только показать, что изменится
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+type ApplicationDiff struct {
+	ApplicationID string        `json:"application_id"`
+	ImportID      string        `json:"import_id"`
+	Changes       []FieldChange `json:"changes"`
+}
+
+// ReprocessSkipped - заявка, raw_row которой текущий разбор не принимает; её поля не меняются
+type ReprocessSkipped struct {
+	ApplicationID string `json:"application_id"`
+	ImportID      string `json:"import_id"`
+	Reason        string `json:"reason"`
+}
+
+// ReprocessConflict - новый external_key заявки уже у другой заявки; заявка не меняется
+type ReprocessConflict struct {
+	ApplicationID string `json:"application_id"`
+	ImportID      string `json:"import_id"`
+	DuplicateOf   string `json:"duplicate_of"`
+}
+
+type ReprocessResponse struct {
+	Applied       bool                `json:"applied"`
+	Scanned       int                 `json:"scanned"`
+	Changed       int                 `json:"changed"`
+	Updated       int                 `json:"updated"`        // при apply
+	SkippedCount  int                 `json:"skipped_count"`  // всего, в skipped — первые из них
+	ConflictCount int                 `json:"conflict_count"` // всего, в conflicts — первые из них
+	Diffs         []ApplicationDiff   `json:"diffs"`          // первые из changed
+	Skipped       []ReprocessSkipped  `json:"skipped"`
+	Conflicts     []ReprocessConflict `json:"conflicts"`
+}
+
+// ApplicationFields - поля заявки, которые извлекаются из строки файла
+type ApplicationFields struct {
+	ApplicationID   uuid.UUID
+	AppliedAt       time.Time
+	ResumeURL       string
+	Priority1       string
+	Priority2       string
+	Course          string
+	Specialty       string
+	SpecialtyOther  string
+	Schedule        string
+	City            string
+	CityOther       string
+	University      string
+	UniversityOther string
+	Source          string
+	ExternalKey     string
+}
+
+// StoredApplication - заявка с исходной строкой файла
+type StoredApplication struct {
+	ImportID    uuid.UUID
+	ProfileCode string
+	Date1904    bool // система дат книги Excel 1904
+	Fields      ApplicationFields
+	RawRow      map[string]any
+}
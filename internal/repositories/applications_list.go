@@ -115,6 +115,11 @@ func (repo *Repository) ListApplications(ctx context.Context, p models.ListAppli
 		conds = append(conds, fmt.Sprintf("a.import_id::text = $%d", i))
 	}
 
+	if strings.TrimSpace(p.StatusChangedBy) != "" {
+		i := addArg(p.StatusChangedBy)
+		conds = append(conds, fmt.Sprintf("a.status_changed_by = $%d::uuid", i))
+	}
+
 	// limit/offset
 	lim := p.Limit
 	off := p.Offset
@@ -148,9 +153,12 @@ func (repo *Repository) ListApplications(ctx context.Context, p models.ListAppli
 
 			(SELECT cc.value FROM candidate_contacts cc
 			  WHERE cc.candidate_id=a.candidate_id AND cc.type='email'
-			  ORDER BY cc.is_primary DESC, cc.created_at DESC
+			  ORDER BY cc.is_invalid, cc.is_primary DESC, cc.created_at DESC
 			  LIMIT 1) AS email,
 
+			(SELECT bool_and(cc.is_invalid) FROM candidate_contacts cc
+			  WHERE cc.candidate_id=a.candidate_id AND cc.type='email') AS email_invalid,
+
 			(SELECT cc.value FROM candidate_contacts cc
 			  WHERE cc.candidate_id=a.candidate_id AND cc.type='phone'
 			  ORDER BY cc.is_primary DESC, cc.created_at DESC
@@ -174,6 +182,7 @@ func (repo *Repository) ListApplications(ctx context.Context, p models.ListAppli
 			a.university_other,
 			a.source,
 			a.status_reason,
+			COALESCE(a.status_changed_by::text, ''),
 
 			COUNT(*) OVER() AS total
 		FROM applications a
@@ -199,6 +208,7 @@ func (repo *Repository) ListApplications(ctx context.Context, p models.ListAppli
 		var langs sql.NullString
 
 		var email sql.NullString
+		var emailInvalid sql.NullBool
 		var phone sql.NullString
 		var tg sql.NullString
 
@@ -232,6 +242,7 @@ func (repo *Repository) ListApplications(ctx context.Context, p models.ListAppli
 			&langs,
 
 			&email,
+			&emailInvalid,
 			&phone,
 			&tg,
 
@@ -248,6 +259,7 @@ func (repo *Repository) ListApplications(ctx context.Context, p models.ListAppli
 			&uniO,
 			&src,
 			&reason,
+			&it.StatusChangedBy,
 
 			&totalRow,
 		)
@@ -268,6 +280,7 @@ func (repo *Repository) ListApplications(ctx context.Context, p models.ListAppli
 		if email.Valid {
 			it.Email = email.String
 		}
+		it.EmailInvalid = emailInvalid.Valid && emailInvalid.Bool
 		if phone.Valid {
 			it.Phone = phone.String
 		}
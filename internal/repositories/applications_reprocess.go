@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+)
+
+// ListStoredApplications - страница заявок с raw_row по application_id после after (keyset);
+// importID = nil — все импорты
+func (repo *Repository) ListStoredApplications(ctx context.Context, importID *uuid.UUID, after uuid.UUID, limit int) ([]models.StoredApplication, error) {
+	const query = `
+		SELECT a.application_id, a.import_id, COALESCE(i.profile_code, ''), COALESCE(i.date1904, false),
+		       a.applied_at,
+		       COALESCE(a.resume_url, ''), COALESCE(a.priority1, ''), COALESCE(a.priority2, ''),
+		       COALESCE(a.course, ''), COALESCE(a.specialty, ''), COALESCE(a.specialty_other, ''),
+		       COALESCE(a.schedule, ''), COALESCE(a.city, ''), COALESCE(a.city_other, ''),
+		       COALESCE(a.university, ''), COALESCE(a.university_other, ''), COALESCE(a.source, ''),
+		       a.external_key, a.raw_row
+		FROM applications a
+		LEFT JOIN imports i ON i.import_id = a.import_id
+		WHERE a.application_id > $1
+		  AND ($2::uuid IS NULL OR a.import_id = $2)
+		ORDER BY a.application_id
+		LIMIT $3
+	`
+	rows, err := repo.pool.Query(ctx, query, after, importID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.StoredApplication
+	for rows.Next() {
+		var a models.StoredApplication
+		f := &a.Fields
+		if err = rows.Scan(&f.ApplicationID, &a.ImportID, &a.ProfileCode, &a.Date1904,
+			&f.AppliedAt,
+			&f.ResumeURL, &f.Priority1, &f.Priority2,
+			&f.Course, &f.Specialty, &f.SpecialtyOther,
+			&f.Schedule, &f.City, &f.CityOther,
+			&f.University, &f.UniversityOther, &f.Source,
+			&f.ExternalKey, &a.RawRow,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// ApplicationsByExternalKey - заявки с такими external_key: external_key -> application_id
+func (repo *Repository) ApplicationsByExternalKey(ctx context.Context, keys []string) (map[string]uuid.UUID, error) {
+	out := map[string]uuid.UUID{}
+	if len(keys) == 0 {
+		return out, nil
+	}
+	rows, err := repo.pool.Query(ctx, `
+		SELECT external_key, application_id FROM applications WHERE external_key = ANY($1::text[])
+	`, keys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			key string
+			id  uuid.UUID
+		)
+		if err = rows.Scan(&key, &id); err != nil {
+			return nil, err
+		}
+		out[key] = id
+	}
+	return out, rows.Err()
+}
+
+// UpdateApplicationFields - перезапись полей заявок, извлечённых из raw_row, вместе с external_key.
+// Если ключ успела занять другая заявка, строка не обновляется и не попадает в n.
+func (repo *Repository) UpdateApplicationFields(ctx context.Context, items []models.ApplicationFields) (n int, err error) {
+	if len(items) == 0 {
+		return 0, nil
+	}
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	const query = `
+		UPDATE applications
+		SET applied_at=$2, resume_url=$3, priority1=$4, priority2=$5, course=$6, specialty=$7,
+		    specialty_other=$8, schedule=$9, city=$10, city_other=$11, university=$12,
+		    university_other=$13, source=$14, external_key=$15, updated_at=now()
+		WHERE application_id=$1
+		  AND NOT EXISTS (SELECT 1 FROM applications o WHERE o.external_key=$15 AND o.application_id<>$1)
+	`
+	b := &pgx.Batch{}
+	for _, f := range items {
+		b.Queue(query, f.ApplicationID, f.AppliedAt, nullIfEmpty(f.ResumeURL),
+			nullIfEmpty(f.Priority1), nullIfEmpty(f.Priority2), nullIfEmpty(f.Course), nullIfEmpty(f.Specialty),
+			nullIfEmpty(f.SpecialtyOther), nullIfEmpty(f.Schedule), nullIfEmpty(f.City), nullIfEmpty(f.CityOther),
+			nullIfEmpty(f.University), nullIfEmpty(f.UniversityOther), nullIfEmpty(f.Source), f.ExternalKey,
+		)
+	}
+	if err = execBatch(ctx, tx, b, func(_ int, ct pgconn.CommandTag) {
+		n += int(ct.RowsAffected())
+	}); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
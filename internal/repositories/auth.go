@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// UpsertUser - пользователь по email (без учёта регистра); пустое имя не затирает сохранённое
+func (repo *Repository) UpsertUser(ctx context.Context, email, name string) (models.User, error) {
+	const query = `
+		INSERT INTO users(user_id, email, name)
+		VALUES($1, $2, $3)
+		ON CONFLICT (lower(email)) DO UPDATE
+		SET name = COALESCE(NULLIF(EXCLUDED.name, ''), users.name), updated_at = now()
+		RETURNING user_id::text, email, name, is_active, created_at
+	`
+	var u models.User
+	err := repo.pool.QueryRow(ctx, query, uuid.New(), email, name).
+		Scan(&u.UserID, &u.Email, &u.Name, &u.IsActive, &u.CreatedAt)
+	return u, err
+}
+
+// IsActiveUser - пользователь есть и не отключён
+func (repo *Repository) IsActiveUser(ctx context.Context, userID uuid.UUID) (bool, error) {
+	var active bool
+	err := repo.pool.QueryRow(ctx, `SELECT is_active FROM users WHERE user_id = $1`, userID).Scan(&active)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return active, err
+}
+
+func (repo *Repository) InsertAPIKey(ctx context.Context, userID uuid.UUID, name, prefix, keyHash string, expiresAt *time.Time) (models.APIKey, error) {
+	const query = `
+		INSERT INTO api_keys(key_id, user_id, name, prefix, key_hash, expires_at)
+		VALUES($1, $2, $3, $4, $5, $6)
+		RETURNING key_id::text, name, prefix, expires_at, last_used_at, revoked_at, created_at
+	`
+	var k models.APIKey
+	err := scanAPIKey(repo.pool.QueryRow(ctx, query, uuid.New(), userID, name, prefix, keyHash, expiresAt), &k)
+	return k, err
+}
+
+// ListAPIKeys - ключи пользователя, включая отозванные
+func (repo *Repository) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]models.APIKey, error) {
+	const query = `
+		SELECT key_id::text, name, prefix, expires_at, last_used_at, revoked_at, created_at
+		FROM api_keys
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := repo.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.APIKey{}
+	for rows.Next() {
+		var k models.APIKey
+		if err = scanAPIKey(rows, &k); err != nil {
+			return nil, err
+		}
+		items = append(items, k)
+	}
+	return items, rows.Err()
+}
+
+// RevokeAPIKey - отзыв ключа его владельцем; повторный отзыв — ErrAPIKeyNotFound
+func (repo *Repository) RevokeAPIKey(ctx context.Context, userID, keyID uuid.UUID) (models.APIKey, error) {
+	const query = `
+		UPDATE api_keys
+		SET revoked_at = now()
+		WHERE key_id = $1 AND user_id = $2 AND revoked_at IS NULL
+		RETURNING key_id::text, name, prefix, expires_at, last_used_at, revoked_at, created_at
+	`
+	var k models.APIKey
+	err := scanAPIKey(repo.pool.QueryRow(ctx, query, keyID, userID), &k)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.APIKey{}, ErrAPIKeyNotFound
+	}
+	return k, err
+}
+
+// UseAPIKey - владелец действующего ключа (не отозван, не истёк, пользователь активен);
+// заодно обновляет last_used_at
+func (repo *Repository) UseAPIKey(ctx context.Context, keyHash string) (uuid.UUID, error) {
+	const query = `
+		UPDATE api_keys k
+		SET last_used_at = now()
+		FROM users u
+		WHERE k.key_hash = $1
+		  AND k.revoked_at IS NULL
+		  AND (k.expires_at IS NULL OR k.expires_at > now())
+		  AND u.user_id = k.user_id
+		  AND u.is_active
+		RETURNING k.user_id
+	`
+	var userID uuid.UUID
+	err := repo.pool.QueryRow(ctx, query, keyHash).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, ErrAPIKeyNotFound
+	}
+	return userID, err
+}
+
+func scanAPIKey(row pgx.Row, k *models.APIKey) error {
+	return row.Scan(&k.KeyID, &k.Name, &k.Prefix, &k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt, &k.CreatedAt)
+}
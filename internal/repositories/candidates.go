@@ -0,0 +1,338 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+)
+
+var ErrCandidateNotFound = errors.New("candidate not found")
+
+// MergeCandidates - переносит заявки (вместе с их заметками) и контакты source в target,
+// дополняет пустые поля профиля target, удаляет source и сохраняет запись о слиянии
+// со снимком source для аудита.
+func (repo *Repository) MergeCandidates(ctx context.Context, sourceID, targetID, mergedBy uuid.UUID) (res models.MergeCandidatesResponse, err error) {
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return res, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	// блокируем обоих в одном порядке, чтобы встречные слияния не дедлочились
+	rows, err := tx.Query(ctx, `
+		SELECT candidate_id FROM candidates
+		WHERE candidate_id = ANY($1::uuid[])
+		ORDER BY candidate_id
+		FOR UPDATE
+	`, []uuid.UUID{sourceID, targetID})
+	if err != nil {
+		return res, err
+	}
+	found := 0
+	for rows.Next() {
+		found++
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return res, err
+	}
+	if found != 2 {
+		return res, ErrCandidateNotFound
+	}
+
+	// снимок source до изменений
+	var snapshot []byte
+	err = tx.QueryRow(ctx, `
+		SELECT jsonb_build_object(
+			'candidate', to_jsonb(c),
+			'contacts', COALESCE((SELECT jsonb_agg(to_jsonb(cc)) FROM candidate_contacts cc WHERE cc.candidate_id = c.candidate_id), '[]'::jsonb),
+			'application_ids', COALESCE((SELECT jsonb_agg(a.application_id) FROM applications a WHERE a.candidate_id = c.candidate_id), '[]'::jsonb)
+		)
+		FROM candidates c
+		WHERE c.candidate_id = $1
+	`, sourceID).Scan(&snapshot)
+	if err != nil {
+		return res, err
+	}
+
+	ct, err := tx.Exec(ctx, `
+		UPDATE applications SET candidate_id=$2, updated_at=now() WHERE candidate_id=$1
+	`, sourceID, targetID)
+	if err != nil {
+		return res, err
+	}
+	res.ApplicationsMoved = int(ct.RowsAffected())
+
+	// (type, normalized) уникален глобально, поэтому совпадающих контактов у source и target быть не может;
+	// конфликтует только is_primary — основным остаётся контакт target
+	ct, err = tx.Exec(ctx, `
+		UPDATE candidate_contacts cc
+		SET candidate_id = $2,
+		    is_primary = cc.is_primary AND NOT EXISTS (
+				SELECT 1 FROM candidate_contacts t
+				WHERE t.candidate_id = $2 AND t.type = cc.type AND t.is_primary
+		    )
+		WHERE cc.candidate_id = $1
+	`, sourceID, targetID)
+	if err != nil {
+		return res, err
+	}
+	res.ContactsMoved = int(ct.RowsAffected())
+
+	_, err = tx.Exec(ctx, `
+		UPDATE candidates t
+		SET birth_year  = COALESCE(t.birth_year, s.birth_year),
+		    citizenship = COALESCE(t.citizenship, s.citizenship),
+		    languages   = COALESCE(t.languages, s.languages),
+		    updated_at  = now()
+		FROM candidates s
+		WHERE t.candidate_id = $2 AND s.candidate_id = $1
+	`, sourceID, targetID)
+	if err != nil {
+		return res, err
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM candidates WHERE candidate_id=$1`, sourceID); err != nil {
+		return res, err
+	}
+
+	mergeID := uuid.New()
+	_, err = tx.Exec(ctx, `
+		INSERT INTO candidate_merges(merge_id, source_candidate_id, target_candidate_id, source_snapshot,
+		                             applications_moved, contacts_moved, merged_by)
+		VALUES($1, $2, $3, $4::jsonb, $5, $6, $7)
+	`, mergeID, sourceID, targetID, string(snapshot), res.ApplicationsMoved, res.ContactsMoved, nullIfNil(mergedBy))
+	if err != nil {
+		return res, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return res, err
+	}
+
+	res.MergeID = mergeID.String()
+	res.TargetCandidateID = targetID.String()
+	return res, nil
+}
+
+// ListDuplicateCandidates - пары с одинаковыми именем и фамилией, у которых совпадает
+// год рождения или часть контакта (локальная часть email, последние 7 цифр телефона)
+func (repo *Repository) ListDuplicateCandidates(ctx context.Context, limit, offset int) ([]models.DuplicatePair, error) {
+	const query = `
+		WITH pairs AS (
+			SELECT
+				a.candidate_id AS a_id,
+				b.candidate_id AS b_id,
+				(a.birth_year IS NOT NULL AND a.birth_year = b.birth_year) AS same_birth_year,
+				EXISTS (
+					SELECT 1
+					FROM candidate_contacts ca
+					JOIN candidate_contacts cb ON cb.type = ca.type AND cb.candidate_id = b.candidate_id
+					WHERE ca.candidate_id = a.candidate_id
+					  AND ca.type = 'email'
+					  AND split_part(ca.normalized, '@', 1) = split_part(cb.normalized, '@', 1)
+				) AS same_email_local,
+				EXISTS (
+					SELECT 1
+					FROM candidate_contacts ca
+					JOIN candidate_contacts cb ON cb.type = ca.type AND cb.candidate_id = b.candidate_id
+					WHERE ca.candidate_id = a.candidate_id
+					  AND ca.type = 'phone'
+					  AND length(ca.normalized) >= 7
+					  AND right(ca.normalized, 7) = right(cb.normalized, 7)
+				) AS same_phone_suffix
+			FROM candidates a
+			JOIN candidates b
+			  ON lower(b.last_name) = lower(a.last_name)
+			 AND lower(b.first_name) = lower(a.first_name)
+			 AND b.candidate_id > a.candidate_id
+			WHERE a.birth_year IS NULL OR b.birth_year IS NULL OR a.birth_year = b.birth_year
+		)
+		SELECT
+			p.a_id::text, ca.first_name, ca.last_name, ca.birth_year,
+			COALESCE((SELECT array_agg(cc.value ORDER BY cc.type, cc.value) FROM candidate_contacts cc WHERE cc.candidate_id = p.a_id), '{}'),
+			p.b_id::text, cb.first_name, cb.last_name, cb.birth_year,
+			COALESCE((SELECT array_agg(cc.value ORDER BY cc.type, cc.value) FROM candidate_contacts cc WHERE cc.candidate_id = p.b_id), '{}'),
+			p.same_birth_year, p.same_email_local, p.same_phone_suffix
+		FROM pairs p
+		JOIN candidates ca ON ca.candidate_id = p.a_id
+		JOIN candidates cb ON cb.candidate_id = p.b_id
+		WHERE p.same_birth_year OR p.same_email_local OR p.same_phone_suffix
+		ORDER BY (p.same_birth_year::int + p.same_email_local::int + p.same_phone_suffix::int) DESC,
+		         ca.last_name, ca.first_name
+		LIMIT $1 OFFSET $2
+	`
+	rows, err := repo.pool.Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.DuplicatePair{}
+	for rows.Next() {
+		var (
+			p                                 models.DuplicatePair
+			sameBirth, sameEmail, samePhoneSx bool
+		)
+		if err = rows.Scan(
+			&p.A.CandidateID, &p.A.FirstName, &p.A.LastName, &p.A.BirthYear, &p.A.Contacts,
+			&p.B.CandidateID, &p.B.FirstName, &p.B.LastName, &p.B.BirthYear, &p.B.Contacts,
+			&sameBirth, &sameEmail, &samePhoneSx,
+		); err != nil {
+			return nil, err
+		}
+
+		p.Reasons = []string{models.DuplicateReasonName}
+		if sameBirth {
+			p.Reasons = append(p.Reasons, models.DuplicateReasonBirthYear)
+		}
+		if sameEmail {
+			p.Reasons = append(p.Reasons, models.DuplicateReasonEmailLocal)
+		}
+		if samePhoneSx {
+			p.Reasons = append(p.Reasons, models.DuplicateReasonPhoneSuffix)
+		}
+		p.Score = len(p.Reasons)
+		out = append(out, p)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return out, nil
+}
+
+// RenormalizeContacts - пересчитывает normalized у контактов одного типа.
+// Если новое значение уже занято контактом того же кандидата, текущая строка удаляется;
+// если другим кандидатом — остаётся как есть и попадает в Conflicts.
+// Контакт, который normalize отверг, помечается is_invalid (и наоборот, пометка снимается).
+// При dryRun транзакция откатывается.
+func (repo *Repository) RenormalizeContacts(ctx context.Context, typ string, normalize func(string) (string, error), dryRun bool) (res models.ContactBackfillResult, err error) {
+	res = models.ContactBackfillResult{
+		Type:      typ,
+		DryRun:    dryRun,
+		Invalid:   []models.ContactBackfillIssue{},
+		Conflicts: []models.ContactBackfillIssue{},
+	}
+
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return res, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	type contact struct {
+		id, candidateID   uuid.UUID
+		value, normalized string
+		invalid           bool
+	}
+
+	// основные и более ранние контакты обрабатываются первыми и остаются при склейке дублей
+	rows, err := tx.Query(ctx, `
+		SELECT contact_id, candidate_id, value, normalized, is_invalid
+		FROM candidate_contacts
+		WHERE type = $1
+		ORDER BY is_primary DESC, created_at, contact_id
+		FOR UPDATE
+	`, typ)
+	if err != nil {
+		return res, err
+	}
+	var contacts []contact
+	for rows.Next() {
+		var c contact
+		if err = rows.Scan(&c.id, &c.candidateID, &c.value, &c.normalized, &c.invalid); err != nil {
+			rows.Close()
+			return res, err
+		}
+		contacts = append(contacts, c)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return res, err
+	}
+	res.Total = len(contacts)
+
+	for _, c := range contacts {
+		issue := models.ContactBackfillIssue{
+			ContactID:   c.id.String(),
+			CandidateID: c.candidateID.String(),
+			Value:       c.value,
+		}
+
+		norm, nerr := normalize(c.value)
+		if nerr != nil {
+			issue.Reason = nerr.Error()
+			res.Invalid = append(res.Invalid, issue)
+			_, err = tx.Exec(ctx, `
+				UPDATE candidate_contacts SET is_invalid=true, invalid_reason=$2 WHERE contact_id=$1
+			`, c.id, issue.Reason)
+			if err != nil {
+				return res, err
+			}
+			continue
+		}
+		if norm == c.normalized {
+			if c.invalid {
+				_, err = tx.Exec(ctx, `
+					UPDATE candidate_contacts SET is_invalid=false, invalid_reason=NULL WHERE contact_id=$1
+				`, c.id)
+				if err != nil {
+					return res, err
+				}
+				res.Updated++
+				continue
+			}
+			res.Unchanged++
+			continue
+		}
+		issue.Normalized = norm
+
+		var ownerID uuid.UUID
+		err = tx.QueryRow(ctx, `
+			SELECT candidate_id FROM candidate_contacts WHERE type=$1 AND normalized=$2
+		`, typ, norm).Scan(&ownerID)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			if _, err = tx.Exec(ctx, `
+				UPDATE candidate_contacts SET normalized=$2, is_invalid=false, invalid_reason=NULL WHERE contact_id=$1
+			`, c.id, norm); err != nil {
+				return res, err
+			}
+			res.Updated++
+		case err != nil:
+			return res, err
+		case ownerID == c.candidateID:
+			if _, err = tx.Exec(ctx, `DELETE FROM candidate_contacts WHERE contact_id=$1`, c.id); err != nil {
+				return res, err
+			}
+			// если удалили основной контакт, основным становится оставшийся
+			_, err = tx.Exec(ctx, `
+				UPDATE candidate_contacts t SET is_primary = true
+				WHERE t.type=$1 AND t.normalized=$2
+				  AND NOT EXISTS (
+					SELECT 1 FROM candidate_contacts p
+					WHERE p.candidate_id = t.candidate_id AND p.type = t.type AND p.is_primary
+				  )
+			`, typ, norm)
+			if err != nil {
+				return res, err
+			}
+			res.Removed++
+		default:
+			issue.Reason = "контакт уже принадлежит кандидату " + ownerID.String()
+			res.Conflicts = append(res.Conflicts, issue)
+		}
+	}
+
+	if dryRun {
+		return res, nil
+	}
+	return res, tx.Commit(ctx)
+}
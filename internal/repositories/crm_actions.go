@@ -26,7 +26,7 @@ type crmRow struct {
 	RawRow    []byte // jsonb в pgx обычно сканится в []byte
 }
 
-func (repo *Repository) QueueCRM(ctx context.Context, appIDs []uuid.UUID) (models.BulkCRMActionResponse, error) {
+func (repo *Repository) QueueCRM(ctx context.Context, appIDs []uuid.UUID, by uuid.UUID) (models.BulkCRMActionResponse, error) {
 	if len(appIDs) == 0 {
 		return models.BulkCRMActionResponse{}, nil
 	}
@@ -147,9 +147,9 @@ func (repo *Repository) QueueCRM(ctx context.Context, appIDs []uuid.UUID) (model
 		payloadJSON := string(b)
 
 		_, exErr := tx.Exec(ctx, `
-			INSERT INTO crm_outbox(crm_id, application_id, payload, status, attempt, created_at, updated_at)
-			VALUES($1, $2, $3::jsonb, 'PENDING', 0, now(), now())
-		`, uuid.New(), r.AppID, payloadJSON)
+			INSERT INTO crm_outbox(crm_id, application_id, payload, status, attempt, created_by, created_at, updated_at)
+			VALUES($1, $2, $3::jsonb, 'PENDING', 0, $4, now(), now())
+		`, uuid.New(), r.AppID, payloadJSON, nullIfNil(by))
 		if exErr != nil {
 			res.Skipped++
 			res.Errors = append(res.Errors, models.ActionItemError{
@@ -161,9 +161,9 @@ func (repo *Repository) QueueCRM(ctx context.Context, appIDs []uuid.UUID) (model
 
 		_, exErr = tx.Exec(ctx, `
 			UPDATE applications
-			SET status=$2, updated_at=now()
+			SET status=$2, status_changed_by=$3, updated_at=now()
 			WHERE application_id=$1
-		`, r.AppID, models.AppCRMQueued)
+		`, r.AppID, models.AppCRMQueued, nullIfNil(by))
 		if exErr != nil {
 			res.Skipped++
 			res.Errors = append(res.Errors, models.ActionItemError{
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+)
+
+// ClaimPendingCRM - забирает пачку записей crm_outbox и блокирует их через locked_until
+func (repo *Repository) ClaimPendingCRM(ctx context.Context, limit int, lockFor time.Duration) ([]models.OutboxCRM, error) {
+	const query = `
+		WITH picked AS (
+			SELECT crm_id
+			FROM crm_outbox
+			WHERE status = $1
+			  AND (next_retry_at IS NULL OR next_retry_at <= now())
+			  AND (locked_until IS NULL OR locked_until < now())
+			ORDER BY created_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE crm_outbox o
+		SET locked_until = now() + make_interval(secs => $3), updated_at = now()
+		FROM picked
+		WHERE o.crm_id = picked.crm_id
+		RETURNING o.crm_id, o.application_id, o.payload, o.attempt
+	`
+	rows, err := repo.pool.Query(ctx, query, models.CRMPending, limit, lockFor.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.OutboxCRM
+	for rows.Next() {
+		var r models.OutboxCRM
+		if err = rows.Scan(&r.CRMID, &r.ApplicationID, &r.Payload, &r.Attempt); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return out, nil
+}
+
+// MarkCRMSynced - CRM подтвердила запись: outbox -> SENT, заявка CRM_QUEUED -> CRM_SYNCED
+func (repo *Repository) MarkCRMSynced(ctx context.Context, crmID, appID uuid.UUID, externalID string) (err error) {
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	_, err = tx.Exec(ctx, `
+		UPDATE crm_outbox
+		SET status=$2, external_id=$3, attempt=attempt+1, last_error=NULL,
+		    locked_until=NULL, next_retry_at=NULL, updated_at=now()
+		WHERE crm_id=$1
+	`, crmID, models.CRMSent, nullIfEmpty(externalID))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE applications
+		SET status=$3, updated_at=now()
+		WHERE application_id=$1 AND status=$2
+	`, appID, models.AppCRMQueued, models.AppCRMSynced)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// MarkCRMFailed - неудачная попытка, следующая не раньше retryAt
+func (repo *Repository) MarkCRMFailed(ctx context.Context, crmID uuid.UUID, lastError string, retryAt time.Time) error {
+	const query = `
+		UPDATE crm_outbox
+		SET attempt=attempt+1, last_error=$2, next_retry_at=$3, locked_until=NULL, updated_at=now()
+		WHERE crm_id=$1
+	`
+	_, err := repo.pool.Exec(ctx, query, crmID, lastError, retryAt)
+	return err
+}
+
+// MarkCRMDead - попытки исчерпаны или CRM отвергла данные
+func (repo *Repository) MarkCRMDead(ctx context.Context, crmID uuid.UUID, lastError string) error {
+	const query = `
+		UPDATE crm_outbox
+		SET status=$2, attempt=attempt+1, last_error=$3, next_retry_at=NULL, locked_until=NULL, updated_at=now()
+		WHERE crm_id=$1
+	`
+	_, err := repo.pool.Exec(ctx, query, crmID, models.CRMDead, lastError)
+	return err
+}
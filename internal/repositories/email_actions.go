@@ -36,18 +36,20 @@ type appRow struct {
 	Status    string
 	FirstName string
 	Email     string
+	Invalid   *string // причина, если у кандидата есть только невалидные email
 }
 
-func (repo *Repository) QueueInviteEmails(ctx context.Context, appIDs []uuid.UUID, templateCode string) (models.BulkEmailActionResponse, error) {
-	return repo.queueEmails(ctx, appIDs, templateCode, models.AppInviteQueued, nil, []string{models.AppInviteQueued, models.AppInvited})
+// by - кто поставил письма (uuid.Nil — не известно); пишется в email_outbox и applications
+func (repo *Repository) QueueInviteEmails(ctx context.Context, appIDs []uuid.UUID, templateCode string, by uuid.UUID) (models.BulkEmailActionResponse, error) {
+	return repo.queueEmails(ctx, appIDs, templateCode, models.AppInviteQueued, nil, []string{models.AppInviteQueued, models.AppInvited}, by)
 }
 
-func (repo *Repository) QueueRejectEmails(ctx context.Context, appIDs []uuid.UUID, templateCode string, reason string) (models.BulkEmailActionResponse, error) {
+func (repo *Repository) QueueRejectEmails(ctx context.Context, appIDs []uuid.UUID, templateCode string, reason string, by uuid.UUID) (models.BulkEmailActionResponse, error) {
 	var r *string
 	if reason != "" {
 		r = &reason
 	}
-	return repo.queueEmails(ctx, appIDs, templateCode, models.AppRejectQueued, r, []string{models.AppRejectQueued, models.AppRejected})
+	return repo.queueEmails(ctx, appIDs, templateCode, models.AppRejectQueued, r, []string{models.AppRejectQueued, models.AppRejected}, by)
 }
 
 func (repo *Repository) queueEmails(
@@ -57,6 +59,7 @@ func (repo *Repository) queueEmails(
 	newStatus string,
 	statusReason *string,
 	skipStatuses []string,
+	by uuid.UUID,
 ) (models.BulkEmailActionResponse, error) {
 
 	if len(appIDs) == 0 {
@@ -78,22 +81,24 @@ func (repo *Repository) queueEmails(
 		return models.BulkEmailActionResponse{}, err
 	}
 
-	// Получаем: статус + first_name + email
+	// Получаем: статус + first_name + email (валидные адреса в приоритете)
 	rows, err := tx.Query(ctx, `
 		SELECT
 			a.application_id,
 			a.candidate_id,
 			a.status,
 			c.first_name,
-			COALESCE((
-				SELECT cc.value
-				FROM candidate_contacts cc
-				WHERE cc.candidate_id = a.candidate_id AND cc.type = 'email'
-				ORDER BY cc.is_primary DESC, cc.created_at DESC
-				LIMIT 1
-			), '') AS email
+			COALESCE(e.value, '') AS email,
+			CASE WHEN e.is_invalid THEN COALESCE(e.invalid_reason, 'невалидный email') END AS invalid
 		FROM applications a
 		JOIN candidates c ON c.candidate_id = a.candidate_id
+		LEFT JOIN LATERAL (
+			SELECT cc.value, cc.is_invalid, cc.invalid_reason
+			FROM candidate_contacts cc
+			WHERE cc.candidate_id = a.candidate_id AND cc.type = 'email'
+			ORDER BY cc.is_invalid, cc.is_primary DESC, cc.created_at DESC
+			LIMIT 1
+		) e ON true
 		WHERE a.application_id = ANY($1::uuid[])
 	`, appIDs)
 	if err != nil {
@@ -103,7 +108,7 @@ func (repo *Repository) queueEmails(
 	var appRows []appRow
 	for rows.Next() {
 		var r appRow
-		if err := rows.Scan(&r.AppID, &r.CandID, &r.Status, &r.FirstName, &r.Email); err != nil {
+		if err := rows.Scan(&r.AppID, &r.CandID, &r.Status, &r.FirstName, &r.Email, &r.Invalid); err != nil {
 			rows.Close()
 			return models.BulkEmailActionResponse{}, err
 		}
@@ -139,6 +144,16 @@ func (repo *Repository) queueEmails(
 			continue
 		}
 
+		// невалидный email — письмо всё равно не дойдёт
+		if r.Invalid != nil {
+			res.Skipped++
+			res.Errors = append(res.Errors, models.ActionItemError{
+				ApplicationID: r.AppID.String(),
+				Error:         fmt.Sprintf("email кандидата невалиден (%s): %s", r.Email, *r.Invalid),
+			})
+			continue
+		}
+
 		vars := map[string]any{
 			"first_name":     r.FirstName,
 			"application_id": r.AppID.String(),
@@ -148,9 +163,9 @@ func (repo *Repository) queueEmails(
 
 		// кладем в outbox (render_vars jsonb)
 		_, exErr := tx.Exec(ctx, `
-			INSERT INTO email_outbox(email_id, application_id, to_email, template_id, render_vars, status)
-			VALUES($1,$2,$3,$4,$5::jsonb,'PENDING')
-		`, uuid.New(), r.AppID, r.Email, tplID, renderVarsJSON)
+			INSERT INTO email_outbox(email_id, application_id, to_email, template_id, render_vars, status, created_by)
+			VALUES($1,$2,$3,$4,$5::jsonb,'PENDING',$6)
+		`, uuid.New(), r.AppID, r.Email, tplID, renderVarsJSON, nullIfNil(by))
 		if exErr != nil {
 			res.Skipped++
 			res.Errors = append(res.Errors, models.ActionItemError{
@@ -164,15 +179,15 @@ func (repo *Repository) queueEmails(
 		if statusReason != nil {
 			_, exErr = tx.Exec(ctx, `
 				UPDATE applications
-				SET status=$2, status_reason=$3, updated_at=now()
+				SET status=$2, status_reason=$3, status_changed_by=$4, updated_at=now()
 				WHERE application_id=$1
-			`, r.AppID, newStatus, *statusReason)
+			`, r.AppID, newStatus, *statusReason, nullIfNil(by))
 		} else {
 			_, exErr = tx.Exec(ctx, `
 				UPDATE applications
-				SET status=$2, updated_at=now()
+				SET status=$2, status_changed_by=$3, updated_at=now()
 				WHERE application_id=$1
-			`, r.AppID, newStatus)
+			`, r.AppID, newStatus, nullIfNil(by))
 		}
 		if exErr != nil {
 			res.Skipped++
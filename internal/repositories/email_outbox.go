@@ -0,0 +1,198 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+)
+
+// ClaimPendingEmails - забирает пачку писем на отправку и блокирует их через locked_until,
+// чтобы параллельные воркеры не отправили одно и то же письмо дважды
+func (repo *Repository) ClaimPendingEmails(ctx context.Context, limit int, lockFor time.Duration) ([]models.OutboxEmail, error) {
+	const query = `
+		WITH picked AS (
+			SELECT email_id
+			FROM email_outbox
+			WHERE status = $1
+			  AND (next_retry_at IS NULL OR next_retry_at <= now())
+			  AND (locked_until IS NULL OR locked_until < now())
+			ORDER BY created_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE email_outbox e
+		SET locked_until = now() + make_interval(secs => $3), updated_at = now()
+		FROM picked, message_templates t
+		WHERE e.email_id = picked.email_id
+		  AND t.template_id = e.template_id
+		RETURNING e.email_id, e.application_id, e.to_email, e.render_vars, e.attempt, t.subject, t.body
+	`
+	rows, err := repo.pool.Query(ctx, query, models.EmailPending, limit, lockFor.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.OutboxEmail
+	for rows.Next() {
+		var (
+			e    models.OutboxEmail
+			vars []byte
+		)
+		if err = rows.Scan(&e.EmailID, &e.ApplicationID, &e.ToEmail, &vars, &e.Attempt, &e.Subject, &e.Body); err != nil {
+			return nil, err
+		}
+		if len(vars) > 0 {
+			if err = json.Unmarshal(vars, &e.RenderVars); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, e)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return out, nil
+}
+
+// MarkEmailSent - письмо отправлено: фиксируем provider_message_id и переводим заявку из *_QUEUED в итоговый статус
+func (repo *Repository) MarkEmailSent(ctx context.Context, emailID, appID uuid.UUID, providerMessageID string) (err error) {
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	_, err = tx.Exec(ctx, `
+		UPDATE email_outbox
+		SET status=$2, provider_message_id=$3, attempt=attempt+1, last_error=NULL,
+		    locked_until=NULL, next_retry_at=NULL, updated_at=now()
+		WHERE email_id=$1
+	`, emailID, models.EmailSent, providerMessageID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE applications
+		SET status = CASE status
+				WHEN $2 THEN $3
+				WHEN $4 THEN $5
+				ELSE status
+			END,
+			updated_at=now()
+		WHERE application_id=$1
+	`, appID, models.AppInviteQueued, models.AppInvited, models.AppRejectQueued, models.AppRejected)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// MarkEmailFailed - неудачная попытка: сохраняем ошибку и откладываем письмо до retryAt
+func (repo *Repository) MarkEmailFailed(ctx context.Context, emailID uuid.UUID, lastError string, retryAt time.Time) error {
+	const query = `
+		UPDATE email_outbox
+		SET attempt=attempt+1, last_error=$2, next_retry_at=$3, locked_until=NULL, updated_at=now()
+		WHERE email_id=$1
+	`
+	_, err := repo.pool.Exec(ctx, query, emailID, lastError, retryAt)
+	return err
+}
+
+// MarkEmailDead - попытки исчерпаны, письмо больше не отправляется автоматически
+func (repo *Repository) MarkEmailDead(ctx context.Context, emailID uuid.UUID, lastError string) error {
+	const query = `
+		UPDATE email_outbox
+		SET status=$2, attempt=attempt+1, last_error=$3, next_retry_at=NULL, locked_until=NULL, updated_at=now()
+		WHERE email_id=$1
+	`
+	_, err := repo.pool.Exec(ctx, query, emailID, models.EmailDead, lastError)
+	return err
+}
+
+// ListDeadEmails - createdBy (uuid строкой) — только письма, поставленные этим пользователем
+func (repo *Repository) ListDeadEmails(ctx context.Context, limit, offset int, createdBy string) (items []models.DeadEmailItem, total int, err error) {
+	const query = `
+		SELECT
+			e.email_id::text,
+			e.application_id::text,
+			e.to_email,
+			t.code,
+			COALESCE(c.first_name, ''),
+			COALESCE(c.last_name, ''),
+			e.attempt,
+			COALESCE(e.last_error, ''),
+			COALESCE(e.created_by::text, ''),
+			e.created_at,
+			e.updated_at,
+			COUNT(*) OVER() AS total
+		FROM email_outbox e
+		JOIN message_templates t ON t.template_id = e.template_id
+		LEFT JOIN applications a ON a.application_id = e.application_id
+		LEFT JOIN candidates c ON c.candidate_id = a.candidate_id
+		WHERE e.status = $1
+		  AND ($4::uuid IS NULL OR e.created_by = $4::uuid)
+		ORDER BY e.updated_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := repo.pool.Query(ctx, query, models.EmailDead, limit, offset, nullIfEmpty(createdBy))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var it models.DeadEmailItem
+		if err = rows.Scan(
+			&it.EmailID,
+			&it.ApplicationID,
+			&it.ToEmail,
+			&it.TemplateCode,
+			&it.FirstName,
+			&it.LastName,
+			&it.Attempt,
+			&it.LastError,
+			&it.CreatedBy,
+			&it.CreatedAt,
+			&it.UpdatedAt,
+			&total,
+		); err != nil {
+			return nil, 0, err
+		}
+		items = append(items, it)
+	}
+	if rows.Err() != nil {
+		return nil, 0, rows.Err()
+	}
+	return items, total, nil
+}
+
+// RequeueDeadEmails - возвращает DEAD письма в очередь с чистым счётчиком попыток
+// и запоминает, кто это сделал. Пустой emailIDs — все DEAD письма.
+func (repo *Repository) RequeueDeadEmails(ctx context.Context, emailIDs []uuid.UUID, requeuedBy uuid.UUID) (int, error) {
+	const query = `
+		UPDATE email_outbox
+		SET status=$2, attempt=0, next_retry_at=NULL, locked_until=NULL, updated_at=now(),
+		    requeued_by=$4, requeued_at=now()
+		WHERE status=$1
+		  AND (cardinality($3::uuid[]) = 0 OR email_id = ANY($3::uuid[]))
+	`
+	if emailIDs == nil {
+		emailIDs = []uuid.UUID{}
+	}
+	ct, err := repo.pool.Exec(ctx, query, models.EmailDead, models.EmailPending, emailIDs, nullIfNil(requeuedBy))
+	if err != nil {
+		return 0, err
+	}
+	return int(ct.RowsAffected()), nil
+}
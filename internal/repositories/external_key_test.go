@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+// ключи, сохранённые до E.164 и нормализации email, должны совпадать с новыми
+func TestBuildExternalKeyStable(t *testing.T) {
+	appliedAt := time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC)
+	legacy := func(base string) string {
+		sum := sha256.Sum256([]byte(base + "|2025-03-01T10:30:00Z|Аналитика|Разработка"))
+		return hex.EncodeToString(sum[:])
+	}
+
+	cases := []struct {
+		name, email, phone, base string
+	}{
+		{"телефон как в файле", "", "8 (916) 123-45-67", "89161234567"},
+		{"телефон с плюсом", "", " +7 916 123-45-67 ", "+79161234567"},
+		{"email в верхнем регистре", " Ivan.Petrov+hh@GMail.com ", "89161234567", "ivan.petrov+hh@gmail.com"},
+		{"email-синоним яндекса", "Ivan.Petrov@ya.ru", "", "ivan.petrov@ya.ru"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := buildExternalKey(c.email, c.phone, appliedAt, " Аналитика", "Разработка ")
+			if want := legacy(c.base); got != want {
+				t.Errorf("buildExternalKey(%q, %q) = %s, want key of %q", c.email, c.phone, got, c.base)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+)
+
+var ErrImportProfileNotFound = errors.New("import profile not found")
+
+const importProfileColumns = `profile_id::text, code, name, columns, date_layouts, is_default, created_at, updated_at`
+
+func scanImportProfile(row pgx.Row) (models.ImportProfile, error) {
+	var (
+		p       models.ImportProfile
+		columns []byte
+	)
+	if err := row.Scan(&p.ProfileID, &p.Code, &p.Name, &columns, &p.DateLayouts, &p.IsDefault, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return models.ImportProfile{}, err
+	}
+	if err := json.Unmarshal(columns, &p.Columns); err != nil {
+		return models.ImportProfile{}, err
+	}
+	return p, nil
+}
+
+func (repo *Repository) GetImportProfileByCode(ctx context.Context, code string) (models.ImportProfile, error) {
+	p, err := scanImportProfile(repo.pool.QueryRow(ctx, `
+		SELECT `+importProfileColumns+` FROM import_profiles WHERE code=$1
+	`, code))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ImportProfile{}, ErrImportProfileNotFound
+	}
+	return p, err
+}
+
+// GetDefaultImportProfile - профиль с is_default=true (не больше одного, см. ux_import_profiles_default)
+func (repo *Repository) GetDefaultImportProfile(ctx context.Context) (models.ImportProfile, error) {
+	p, err := scanImportProfile(repo.pool.QueryRow(ctx, `
+		SELECT `+importProfileColumns+` FROM import_profiles WHERE is_default=true
+	`))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ImportProfile{}, ErrImportProfileNotFound
+	}
+	return p, err
+}
+
+func (repo *Repository) ListImportProfiles(ctx context.Context) ([]models.ImportProfile, error) {
+	rows, err := repo.pool.Query(ctx, `
+		SELECT `+importProfileColumns+` FROM import_profiles ORDER BY code
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.ImportProfile{}
+	for rows.Next() {
+		p, err := scanImportProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return out, nil
+}
+
+// UpsertImportProfile - создаёт или обновляет профиль по code.
+// Если профиль помечен is_default, флаг снимается с остальных.
+func (repo *Repository) UpsertImportProfile(ctx context.Context, p models.ImportProfile) (res models.ImportProfile, err error) {
+	columns, err := json.Marshal(p.Columns)
+	if err != nil {
+		return models.ImportProfile{}, err
+	}
+
+	dateLayouts := p.DateLayouts
+	if dateLayouts == nil {
+		dateLayouts = []string{}
+	}
+
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return models.ImportProfile{}, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if p.IsDefault {
+		_, err = tx.Exec(ctx, `
+			UPDATE import_profiles SET is_default=false, updated_at=now()
+			WHERE is_default=true AND code<>$1
+		`, p.Code)
+		if err != nil {
+			return models.ImportProfile{}, err
+		}
+	}
+
+	res, err = scanImportProfile(tx.QueryRow(ctx, `
+		INSERT INTO import_profiles(profile_id, code, name, columns, date_layouts, is_default)
+		VALUES($1, $2, $3, $4::jsonb, $5, $6)
+		ON CONFLICT (code) DO UPDATE
+			SET name=EXCLUDED.name,
+			    columns=EXCLUDED.columns,
+			    date_layouts=EXCLUDED.date_layouts,
+			    is_default=EXCLUDED.is_default,
+			    updated_at=now()
+		RETURNING `+importProfileColumns, uuid.New(), p.Code, p.Name, string(columns), dateLayouts, p.IsDefault))
+	if err != nil {
+		return models.ImportProfile{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return models.ImportProfile{}, err
+	}
+	return res, nil
+}
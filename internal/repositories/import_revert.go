@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+)
+
+var (
+	ErrImportNotRevertible       = errors.New("import cannot be reverted")
+	ErrIntakeImportNotRevertible = errors.New("webhook import cannot be reverted")
+	ErrRevertCandidatesLocked    = errors.New("candidates are locked by a running import")
+)
+
+// lockNotAvailable - SQLSTATE ошибки FOR UPDATE NOWAIT
+const lockNotAvailable = "55P03"
+
+// RevertImport - удаляет заявки импорта, которые ещё никто не трогал (NEW, без писем, CRM и заметок),
+// и кандидатов, у которых после этого не осталось заявок, вместе с их контактами.
+// Остальные заявки остаются и перечисляются в Kept. Изменения профиля найденных
+// по контактам кандидатов и добавленные им контакты не откатываются.
+// Суточный импорт webhook не откатывается: в него продолжают писаться заявки с формы.
+// Идущий импорт держит FOR NO KEY UPDATE на кандидатах, найденных по контактам (candidatesByContacts),
+// поэтому кандидат, которому он добавляет заявку, не удалится как оставшийся без заявок:
+// откат в этом случае сразу завершается ErrRevertCandidatesLocked, а не ждёт импорт.
+func (repo *Repository) RevertImport(ctx context.Context, importID, revertedBy uuid.UUID) (res models.RevertImportResponse, err error) {
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return res, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	var status, profileCode string
+	err = tx.QueryRow(ctx, `
+		SELECT status, COALESCE(profile_code, '') FROM imports WHERE import_id = $1 FOR UPDATE
+	`, importID).Scan(&status, &profileCode)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return res, ErrImportNotFound
+	}
+	if err != nil {
+		return res, err
+	}
+	if profileCode == models.IntakeProfileCode {
+		return res, ErrIntakeImportNotRevertible
+	}
+	if status != models.FileParsed && status != models.FilePartial {
+		return res, fmt.Errorf("%w: status %s", ErrImportNotRevertible, status)
+	}
+
+	// блокируем заявки: приглашение или отправка в CRM не должны проскочить между проверкой и удалением
+	rows, err := tx.Query(ctx, `
+		SELECT a.application_id, a.candidate_id, a.status,
+		       EXISTS (SELECT 1 FROM email_outbox e WHERE e.application_id = a.application_id),
+		       EXISTS (SELECT 1 FROM crm_outbox c WHERE c.application_id = a.application_id),
+		       EXISTS (SELECT 1 FROM application_notes n WHERE n.application_id = a.application_id)
+		FROM applications a
+		WHERE a.import_id = $1
+		ORDER BY a.application_id
+		FOR UPDATE OF a
+	`, importID)
+	if err != nil {
+		return res, err
+	}
+	var remove []uuid.UUID
+	res.Kept = []models.RevertKeptApplication{}
+	for rows.Next() {
+		var (
+			appID, candidateID         uuid.UUID
+			appStatus                  string
+			hasEmails, hasCRM, hasNote bool
+		)
+		if err = rows.Scan(&appID, &candidateID, &appStatus, &hasEmails, &hasCRM, &hasNote); err != nil {
+			rows.Close()
+			return res, err
+		}
+
+		var reasons []string
+		if appStatus != models.AppNew {
+			reasons = append(reasons, models.RevertKeptNotNew)
+		}
+		if hasEmails {
+			reasons = append(reasons, models.RevertKeptEmails)
+		}
+		if hasCRM {
+			reasons = append(reasons, models.RevertKeptCRM)
+		}
+		if hasNote {
+			reasons = append(reasons, models.RevertKeptNotes)
+		}
+		if len(reasons) == 0 {
+			remove = append(remove, appID)
+			continue
+		}
+		res.Kept = append(res.Kept, models.RevertKeptApplication{
+			ApplicationID: appID.String(),
+			CandidateID:   candidateID.String(),
+			Status:        appStatus,
+			Reasons:       reasons,
+		})
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return res, err
+	}
+
+	if len(remove) > 0 {
+		rows, err = tx.Query(ctx, `
+			DELETE FROM applications
+			WHERE application_id = ANY($1::uuid[])
+			RETURNING candidate_id
+		`, remove)
+		if err != nil {
+			return res, err
+		}
+		seen := map[uuid.UUID]struct{}{}
+		var candidates []uuid.UUID
+		for rows.Next() {
+			var id uuid.UUID
+			if err = rows.Scan(&id); err != nil {
+				rows.Close()
+				return res, err
+			}
+			res.ApplicationsDeleted++
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				candidates = append(candidates, id)
+			}
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return res, err
+		}
+
+		// сначала блокировка, потом проверка заявок отдельным запросом: он видит заявки,
+		// которые импорт успел закоммитить, а новые импорты ждут конца отката
+		if _, err = tx.Exec(ctx, `
+			SELECT 1 FROM candidates
+			WHERE candidate_id = ANY($1::uuid[])
+			ORDER BY candidate_id
+			FOR UPDATE NOWAIT
+		`, candidates); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == lockNotAvailable {
+				return res, ErrRevertCandidatesLocked
+			}
+			return res, err
+		}
+
+		// кандидат без заявок больше ни к чему не относится
+		err = tx.QueryRow(ctx, `
+			WITH orphans AS (
+				SELECT c.candidate_id
+				FROM candidates c
+				WHERE c.candidate_id = ANY($1::uuid[])
+				  AND NOT EXISTS (SELECT 1 FROM applications a WHERE a.candidate_id = c.candidate_id)
+			), contacts AS (
+				DELETE FROM candidate_contacts cc
+				USING orphans o
+				WHERE cc.candidate_id = o.candidate_id
+				RETURNING 1
+			), deleted AS (
+				DELETE FROM candidates c
+				USING orphans o
+				WHERE c.candidate_id = o.candidate_id
+				RETURNING 1
+			)
+			SELECT (SELECT COUNT(*) FROM deleted), (SELECT COUNT(*) FROM contacts)
+		`, candidates).Scan(&res.CandidatesDeleted, &res.ContactsDeleted)
+		if err != nil {
+			return res, err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE imports SET status = $2, reverted_at = now(), reverted_by = $3 WHERE import_id = $1
+	`, importID, models.FileReverted, nullIfNil(revertedBy))
+	if err != nil {
+		return res, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return res, err
+	}
+	res.ImportID = importID.String()
+	res.Status = models.FileReverted
+	return res, nil
+}
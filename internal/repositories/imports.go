@@ -2,35 +2,398 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
 )
 
-// InsertImport - вставка метаданных об документе
+// SQLSTATE нарушения уникального индекса
+const uniqueViolation = "23505"
+
+var (
+	ErrImportNotFound      = errors.New("import not found")
+	ErrImportFileDuplicate = errors.New("same file is already imported")
+)
+
+// InsertImport - вставка метаданных об документе. Если тот же файл уже импортирован
+// (или импортируется прямо сейчас) без force — ErrImportFileDuplicate.
 func (repo *Repository) InsertImport(ctx context.Context, fileMetadata *models.FileMetaData) error {
 	const query = `
-		INSERT INTO imports(import_id, uploaded_by, file_name, file_sha256, status, total_rows, inserted_rows, skipped_rows)
-		VALUES($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO imports(import_id, uploaded_by, file_name, file_sha256, status, total_rows, inserted_rows, skipped_rows,
+		                    profile_code, forced, owner)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 	_, err := repo.pool.Exec(
-		ctx, query, fileMetadata.ImportID, nil, fileMetadata.FileName, fileMetadata.FileSha256, fileMetadata.Status,
-		fileMetadata.TotalRows, fileMetadata.InsertedRows, fileMetadata.SkippedRows)
+		ctx, query, fileMetadata.ImportID, nullIfNil(fileMetadata.UploadedBy), fileMetadata.FileName, fileMetadata.FileSha256, fileMetadata.Status,
+		fileMetadata.TotalRows, fileMetadata.InsertedRows, fileMetadata.SkippedRows, nullIfEmpty(fileMetadata.ProfileCode),
+		fileMetadata.Forced, nullIfEmpty(fileMetadata.Owner))
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == "ux_imports_file_sha256_active" {
+		return ErrImportFileDuplicate
+	}
+	return err
+}
+
+// SetImportFailed - вставка идёт одной транзакцией, поэтому при ошибке из файла ничего не сохранено
+func (repo *Repository) SetImportFailed(ctx context.Context, importID uuid.UUID, reason string) error {
+	const query = `
+		UPDATE imports
+		SET status=$2, error=$3, processed_rows=0, inserted_rows=0, skipped_rows=0,
+		    candidates_created=0, candidates_matched=0, candidates_updated=0, finished_at=now()
+		WHERE import_id=$1
+	`
+	_, err := repo.pool.Exec(ctx, query, importID, models.FileFailed, nullIfEmpty(reason))
+	return err
+}
 
+// SetImportProcessing - воркер взял импорт в работу
+func (repo *Repository) SetImportProcessing(ctx context.Context, importID uuid.UUID) error {
+	const query = `UPDATE imports SET status=$2, started_at=now() WHERE import_id=$1`
+	_, err := repo.pool.Exec(ctx, query, importID, models.FileProcessing)
 	return err
 }
 
-func (repo *Repository) SetImportFailed(ctx context.Context, importID uuid.UUID, status string) error {
-	const query = `UPDATE imports SET status=$2 WHERE import_id=$1`
-	_, err := repo.pool.Exec(ctx, query, importID, status)
+// SetImportTotal - сколько валидных строк будет вставляться (известно после разбора файла)
+func (repo *Repository) SetImportTotal(ctx context.Context, importID uuid.UUID, total int) error {
+	const query = `UPDATE imports SET total_rows=$2 WHERE import_id=$1`
+	_, err := repo.pool.Exec(ctx, query, importID, total)
+	return err
+}
+
+// SetImportProgress - промежуточные счётчики во время вставки
+func (repo *Repository) SetImportProgress(ctx context.Context, importID uuid.UUID, processed int, stats models.InsertStats) error {
+	const query = `
+		UPDATE imports
+		SET processed_rows=$2, inserted_rows=$3, skipped_rows=$4, candidates_created=$5, candidates_matched=$6,
+		    failed_rows=$7, candidates_updated=$8
+		WHERE import_id=$1
+	`
+	_, err := repo.pool.Exec(ctx, query, importID, processed, stats.Inserted, stats.Skipped,
+		stats.CandidatesCreated, stats.CandidatesMatched, stats.Failed, stats.CandidatesUpdated)
 	return err
 }
 
-func (repo *Repository) SetImportStats(ctx context.Context, importID uuid.UUID, status string, total, inserted, skipped int) error {
+func (repo *Repository) SetImportStats(ctx context.Context, importID uuid.UUID, status string, total int, stats models.InsertStats) error {
 	const query = `
 		UPDATE imports
-		SET status=$2, total_rows=$3, inserted_rows=$4, skipped_rows=$5
+		SET status=$2, total_rows=$3, inserted_rows=$4, skipped_rows=$5, failed_rows=$8,
+		    processed_rows=$4+$5+$8, candidates_created=$6, candidates_matched=$7, candidates_updated=$9,
+		    finished_at=now()
 		WHERE import_id=$1
 	`
-	_, err := repo.pool.Exec(ctx, query, importID, status, total, inserted, skipped)
+	_, err := repo.pool.Exec(ctx, query, importID, status, total, stats.Inserted, stats.Skipped,
+		stats.CandidatesCreated, stats.CandidatesMatched, stats.Failed, stats.CandidatesUpdated)
+	return err
+}
+
+// SetImportHeaders - заголовки листов файла и система дат книги,
+// по ним строится отчёт об ошибках и повторно разбирается raw_row
+func (repo *Repository) SetImportHeaders(ctx context.Context, importID uuid.UUID, headers map[string][]string, date1904 bool) error {
+	const query = `UPDATE imports SET headers=$2, date1904=$3 WHERE import_id=$1`
+	_, err := repo.pool.Exec(ctx, query, importID, headers, date1904)
+	return err
+}
+
+// SetImportSheets - итоги по листам книги xlsx
+func (repo *Repository) SetImportSheets(ctx context.Context, importID uuid.UUID, sheets []models.SheetStats) error {
+	const query = `UPDATE imports SET sheets=$2 WHERE import_id=$1`
+	_, err := repo.pool.Exec(ctx, query, importID, sheets)
 	return err
 }
+
+// FailInterruptedImports - импорты owner, которые были в очереди или в работе при остановке сервиса,
+// уже никто не доделает (очередь в памяти). Импорты без owner созданы до его появления.
+func (repo *Repository) FailInterruptedImports(ctx context.Context, owner string) (int, error) {
+	const query = `
+		UPDATE imports
+		SET status=$3, error='импорт прерван перезапуском сервиса', finished_at=now()
+		WHERE status IN ($1, $2) AND (owner = $4 OR owner IS NULL)
+	`
+	ct, err := repo.pool.Exec(ctx, query, models.FileQueued, models.FileProcessing, models.FileFailed, owner)
+	if err != nil {
+		return 0, err
+	}
+	return int(ct.RowsAffected()), nil
+}
+
+// FindImportBySha256 - последний импорт того же файла, кроме упавших (они ничего не сохранили)
+// и откаченных
+func (repo *Repository) FindImportBySha256(ctx context.Context, fileSha256 string) (models.ImportProgress, bool, error) {
+	query := `SELECT ` + importProgressColumns + `
+		FROM imports i
+		WHERE i.file_sha256 = $1 AND i.status NOT IN ($2, $3)
+		ORDER BY i.created_at DESC
+		LIMIT 1
+	`
+	var p models.ImportProgress
+	if err := scanImportProgress(repo.pool.QueryRow(ctx, query, fileSha256, models.FileFailed, models.FileReverted), &p); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ImportProgress{}, false, nil
+		}
+		return models.ImportProgress{}, false, err
+	}
+	return p, true, nil
+}
+
+// InsertImportErrors - сохраняет ошибки разбора строк
+func (repo *Repository) InsertImportErrors(ctx context.Context, importID uuid.UUID, rowErrors []models.ImportRowError) error {
+	return copyImportErrors(ctx, repo.pool, importID, rowErrors)
+}
+
+func copyImportErrors(ctx context.Context, db interface {
+	CopyFrom(context.Context, pgx.Identifier, []string, pgx.CopyFromSource) (int64, error)
+}, importID uuid.UUID, rowErrors []models.ImportRowError) error {
+	if len(rowErrors) == 0 {
+		return nil
+	}
+	_, err := db.CopyFrom(ctx,
+		pgx.Identifier{"import_errors"},
+		[]string{"error_id", "import_id", "sheet", "row_num", "column_name", "code", "message", "rejected", "cells"},
+		pgx.CopyFromSlice(len(rowErrors), func(i int) ([]any, error) {
+			e := rowErrors[i]
+			return []any{uuid.New(), importID, nullIfEmpty(e.Sheet), e.Row, nullIfEmpty(e.Column), e.Code, e.Message, e.Rejected, e.Cells}, nil
+		}),
+	)
+	return err
+}
+
+const importProgressColumns = `
+	i.import_id::text,
+	COALESCE(i.uploaded_by::text, ''),
+	i.file_name,
+	i.file_sha256,
+	i.status,
+	i.total_rows,
+	i.processed_rows,
+	i.inserted_rows,
+	i.skipped_rows,
+	i.failed_rows,
+	i.candidates_created,
+	i.candidates_matched,
+	i.candidates_updated,
+	(SELECT COUNT(*) FROM import_errors ie WHERE ie.import_id = i.import_id),
+	COALESCE(i.error, ''),
+	i.created_at,
+	i.started_at,
+	i.finished_at,
+	i.sheets,
+	COALESCE(i.reverted_by::text, '')
+`
+
+func scanImportProgress(row pgx.Row, p *models.ImportProgress, extra ...any) error {
+	dest := []any{
+		&p.ImportID,
+		&p.UploadedBy,
+		&p.FileName,
+		&p.FileSha256,
+		&p.Status,
+		&p.TotalRows,
+		&p.ProcessedRows,
+		&p.InsertedRows,
+		&p.SkippedRows,
+		&p.FailedRows,
+		&p.CandidatesCreated,
+		&p.CandidatesMatched,
+		&p.CandidatesUpdated,
+		&p.ErrorsCount,
+		&p.Error,
+		&p.CreatedAt,
+		&p.StartedAt,
+		&p.FinishedAt,
+		&p.Sheets,
+		&p.RevertedBy,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
+
+func (repo *Repository) GetImportProgress(ctx context.Context, importID uuid.UUID) (models.ImportProgress, error) {
+	query := `SELECT ` + importProgressColumns + ` FROM imports i WHERE i.import_id = $1`
+
+	var p models.ImportProgress
+	if err := scanImportProgress(repo.pool.QueryRow(ctx, query, importID), &p); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ImportProgress{}, ErrImportNotFound
+		}
+		return models.ImportProgress{}, err
+	}
+	return p, nil
+}
+
+// ListImports - история импортов, новые сверху (ix_imports_created_at)
+func (repo *Repository) ListImports(ctx context.Context, p models.ListImportsParams) (items []models.ImportProgress, total int, err error) {
+	conds := []string{"1=1"}
+	args := make([]any, 0, 6)
+
+	addArg := func(v any) int {
+		args = append(args, v)
+		return len(args)
+	}
+
+	if len(p.Statuses) > 0 {
+		conds = append(conds, fmt.Sprintf("i.status = ANY($%d::text[])", addArg(p.Statuses)))
+	}
+	if p.UploadedBy != "" {
+		conds = append(conds, fmt.Sprintf("i.uploaded_by = $%d::uuid", addArg(p.UploadedBy)))
+	}
+	if p.CreatedFrom != nil {
+		conds = append(conds, fmt.Sprintf("i.created_at >= $%d", addArg(*p.CreatedFrom)))
+	}
+	if p.CreatedTo != nil {
+		conds = append(conds, fmt.Sprintf("i.created_at <= $%d", addArg(*p.CreatedTo)))
+	}
+
+	lim := p.Limit
+	off := p.Offset
+	if lim <= 0 {
+		lim = 50
+	}
+	if lim > 200 {
+		lim = 200
+	}
+	if off < 0 {
+		off = 0
+	}
+	iLim := addArg(lim)
+	iOff := addArg(off)
+
+	qry := fmt.Sprintf(`
+		SELECT %s, COUNT(*) OVER() AS total
+		FROM imports i
+		WHERE %s
+		ORDER BY i.created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, importProgressColumns, strings.Join(conds, " AND "), iLim, iOff)
+
+	rows, err := repo.pool.Query(ctx, qry, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	items = []models.ImportProgress{}
+	for rows.Next() {
+		var (
+			it       models.ImportProgress
+			totalRow int64
+		)
+		if err = scanImportProgress(rows, &it, &totalRow); err != nil {
+			return nil, 0, err
+		}
+		total = int(totalRow)
+		items = append(items, it)
+	}
+	if rows.Err() != nil {
+		return nil, 0, rows.Err()
+	}
+	return items, total, nil
+}
+
+// GetImportApplicationsSummary - заявки, созданные импортом, в разрезе текущего статуса
+func (repo *Repository) GetImportApplicationsSummary(ctx context.Context, importID uuid.UUID) (models.ImportApplicationsSummary, error) {
+	const query = `
+		SELECT a.status, COUNT(*)
+		FROM applications a
+		WHERE a.import_id = $1
+		GROUP BY a.status
+	`
+	rows, err := repo.pool.Query(ctx, query, importID)
+	if err != nil {
+		return models.ImportApplicationsSummary{}, err
+	}
+	defer rows.Close()
+
+	res := models.ImportApplicationsSummary{ByStatus: map[string]int{}}
+	for rows.Next() {
+		var (
+			status string
+			cnt    int
+		)
+		if err = rows.Scan(&status, &cnt); err != nil {
+			return models.ImportApplicationsSummary{}, err
+		}
+		res.ByStatus[status] = cnt
+		res.Total += cnt
+	}
+	if rows.Err() != nil {
+		return models.ImportApplicationsSummary{}, rows.Err()
+	}
+
+	// кандидат может иметь заявки в разных статусах — считаем отдельно
+	err = repo.pool.QueryRow(ctx, `
+		SELECT COUNT(DISTINCT candidate_id) FROM applications WHERE import_id = $1
+	`, importID).Scan(&res.Candidates)
+	if err != nil {
+		return models.ImportApplicationsSummary{}, err
+	}
+	return res, nil
+}
+
+func (repo *Repository) ListImportErrors(ctx context.Context, importID uuid.UUID, limit int) ([]models.ImportRowError, error) {
+	const query = `
+		SELECT COALESCE(sheet, ''), row_num, COALESCE(column_name, ''), code, message, rejected
+		FROM import_errors
+		WHERE import_id = $1
+		ORDER BY sheet NULLS FIRST, row_num
+		LIMIT $2
+	`
+	rows, err := repo.pool.Query(ctx, query, importID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.ImportRowError{}
+	for rows.Next() {
+		var e models.ImportRowError
+		if err = rows.Scan(&e.Sheet, &e.Row, &e.Column, &e.Code, &e.Message, &e.Rejected); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return out, nil
+}
+
+// GetImportErrorReport - отклонённые строки импорта вместе с исходными ячейками
+func (repo *Repository) GetImportErrorReport(ctx context.Context, importID uuid.UUID) (models.ImportErrorReport, error) {
+	var rep models.ImportErrorReport
+	err := repo.pool.QueryRow(ctx,
+		`SELECT file_name, COALESCE(profile_code, ''), headers, date1904 FROM imports WHERE import_id = $1`,
+		importID,
+	).Scan(&rep.FileName, &rep.ProfileCode, &rep.Headers, &rep.Date1904)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return rep, ErrImportNotFound
+	}
+	if err != nil {
+		return rep, err
+	}
+
+	const query = `
+		SELECT COALESCE(sheet, ''), row_num, COALESCE(column_name, ''), code, message, COALESCE(cells, '{}')
+		FROM import_errors
+		WHERE import_id = $1 AND rejected
+		ORDER BY sheet NULLS FIRST, row_num
+	`
+	rows, err := repo.pool.Query(ctx, query, importID)
+	if err != nil {
+		return rep, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e := models.ImportRowError{Rejected: true}
+		if err = rows.Scan(&e.Sheet, &e.Row, &e.Column, &e.Code, &e.Message, &e.Cells); err != nil {
+			return rep, err
+		}
+		rep.Rows = append(rep.Rows, e)
+	}
+	return rep, rows.Err()
+}
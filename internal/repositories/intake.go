@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+)
+
+// EnsureImport - запись импорта, если её ещё нет. id детерминированный (суточный импорт webhook),
+// поэтому одновременные заявки создают одну запись.
+func (repo *Repository) EnsureImport(ctx context.Context, fileMetadata *models.FileMetaData) error {
+	const query = `
+		INSERT INTO imports(import_id, file_name, file_sha256, status, profile_code, started_at, finished_at)
+		VALUES($1, $2, $3, $4, $5, now(), now())
+		ON CONFLICT (import_id) DO NOTHING
+	`
+	_, err := repo.pool.Exec(ctx, query, fileMetadata.ImportID, fileMetadata.FileName, fileMetadata.FileSha256,
+		fileMetadata.Status, nullIfEmpty(fileMetadata.ProfileCode))
+	return err
+}
+
+// AddImportStats - прибавляет счётчики записанного к импорту в той же транзакции
+// (импорт пополняется по одной заявке, а не пишется целиком)
+func (w *ImportWriter) AddImportStats(ctx context.Context, total int) error {
+	const query = `
+		UPDATE imports
+		SET total_rows=total_rows+$2, inserted_rows=inserted_rows+$3, skipped_rows=skipped_rows+$4,
+		    failed_rows=failed_rows+$5, processed_rows=processed_rows+$3+$4+$5,
+		    candidates_created=candidates_created+$6, candidates_matched=candidates_matched+$7,
+		    candidates_updated=candidates_updated+$8,
+		    finished_at=now()
+		WHERE import_id=$1
+	`
+	_, err := w.tx.Exec(ctx, query, w.importID, total, w.stats.Inserted, w.stats.Skipped, w.stats.Failed,
+		w.stats.CandidatesCreated, w.stats.CandidatesMatched, w.stats.CandidatesUpdated)
+	return err
+}
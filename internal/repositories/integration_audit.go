@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+)
+
+func (repo *Repository) InsertIntegrationAudit(ctx context.Context, a models.IntegrationAudit) error {
+	const query = `
+		INSERT INTO integration_audit(audit_id, system, entity_id, request, response, http_status)
+		VALUES($1, $2, $3, $4::jsonb, $5::jsonb, $6)
+	`
+	req, err := auditJSON(a.Request)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+	resp, err := auditJSON(a.Response)
+	if err != nil {
+		return fmt.Errorf("marshal response: %w", err)
+	}
+
+	_, err = repo.pool.Exec(ctx, query, uuid.New(), a.System, a.EntityID, req, resp, a.HTTPStatus)
+	return err
+}
+
+// auditJSON - nil остаётся NULL, готовый json кладём как есть
+func auditJSON(v any) (any, error) {
+	switch x := v.(type) {
+	case nil:
+		return nil, nil
+	case json.RawMessage:
+		if len(x) == 0 {
+			return nil, nil
+		}
+		return string(x), nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (repo *Repository) ListIntegrationAudit(ctx context.Context, p models.ListIntegrationAuditParams) (items []models.IntegrationAuditItem, total int, err error) {
+	conds := []string{"1=1"}
+	args := make([]any, 0, 8)
+
+	addArg := func(v any) int {
+		args = append(args, v)
+		return len(args)
+	}
+
+	if s := strings.TrimSpace(p.System); s != "" {
+		conds = append(conds, fmt.Sprintf("ia.system = $%d", addArg(s)))
+	}
+	if s := strings.TrimSpace(p.EntityID); s != "" {
+		conds = append(conds, fmt.Sprintf("ia.entity_id::text = $%d", addArg(s)))
+	}
+	if s := strings.TrimSpace(p.CandidateID); s != "" {
+		conds = append(conds, fmt.Sprintf(`
+			ia.entity_id IN (
+				SELECT a.application_id FROM applications a
+				WHERE a.candidate_id::text = $%d
+			)
+		`, addArg(s)))
+	}
+	if p.From != nil {
+		conds = append(conds, fmt.Sprintf("ia.created_at >= $%d", addArg(*p.From)))
+	}
+	if p.To != nil {
+		conds = append(conds, fmt.Sprintf("ia.created_at <= $%d", addArg(*p.To)))
+	}
+
+	lim := p.Limit
+	off := p.Offset
+	if lim <= 0 {
+		lim = 50
+	}
+	if lim > 200 {
+		lim = 200
+	}
+	if off < 0 {
+		off = 0
+	}
+	iLim := addArg(lim)
+	iOff := addArg(off)
+
+	qry := fmt.Sprintf(`
+		SELECT
+			ia.audit_id::text,
+			ia.system,
+			ia.entity_id::text,
+			ia.request,
+			ia.response,
+			ia.http_status,
+			ia.created_at,
+			COUNT(*) OVER() AS total
+		FROM integration_audit ia
+		WHERE %s
+		ORDER BY ia.created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, strings.Join(conds, " AND "), iLim, iOff)
+
+	rows, err := repo.pool.Query(ctx, qry, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			it       models.IntegrationAuditItem
+			req      []byte
+			resp     []byte
+			totalRow int64
+		)
+		if err = rows.Scan(&it.AuditID, &it.System, &it.EntityID, &req, &resp, &it.HTTPStatus, &it.CreatedAt, &totalRow); err != nil {
+			return nil, 0, err
+		}
+		it.Request = req
+		it.Response = resp
+		total = int(totalRow)
+		items = append(items, it)
+	}
+	if rows.Err() != nil {
+		return nil, 0, rows.Err()
+	}
+	return items, total, nil
+}
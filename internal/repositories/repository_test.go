@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsRowError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"неверная дата", &pgconn.PgError{Code: "22007"}, true},
+		{"слишком длинная строка", fmt.Errorf("insert: %w", &pgconn.PgError{Code: "22001"}), true},
+		{"уникальный индекс", &pgconn.PgError{Code: uniqueViolation}, true},
+		{"взаимоблокировка", &pgconn.PgError{Code: "40P01"}, false},
+		{"блокировка занята", &pgconn.PgError{Code: lockNotAvailable}, false},
+		{"отмена запроса", &pgconn.PgError{Code: "57014"}, false},
+		{"соединение", errors.New("conn closed"), false},
+	}
+	for _, c := range cases {
+		if got := isRowError(c.err); got != c.want {
+			t.Errorf("%s: isRowError = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
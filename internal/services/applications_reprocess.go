@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/repositories"
+)
+
+const (
+	reprocessPageSize      = 500
+	reprocessDiffLimit     = 200 // сколько изменённых заявок показываем в ответе
+	reprocessSkipLimit     = 200
+	reprocessConflictLimit = 200
+)
+
+var ErrNothingToReprocess = errors.New("import_id или all обязательны")
+
+// ReprocessApplications - заново извлекает поля заявок из сохранённого raw_row текущим разбором
+// (профиль импорта, форматы дат, нормализация). Без Apply только показывает, что изменится.
+// external_key пересчитывается вместе с полями; если новый ключ уже у другой заявки,
+// заявка не меняется и попадает в Conflicts (это дубль, его можно откатить или слить).
+// Кандидаты и контакты не трогаются: для контактов есть backfill-contacts.
+func (s *Service) ReprocessApplications(ctx context.Context, req models.ReprocessRequest) (models.ReprocessResponse, error) {
+	if !req.All && req.ImportID == "" {
+		return models.ReprocessResponse{}, ErrNothingToReprocess
+	}
+
+	var importID *uuid.UUID
+	if !req.All {
+		id, err := uuid.Parse(req.ImportID)
+		if err != nil {
+			return models.ReprocessResponse{}, err
+		}
+		if _, err = s.repo.GetImportProgress(ctx, id); err != nil {
+			return models.ReprocessResponse{}, err
+		}
+		importID = &id
+	}
+
+	res := models.ReprocessResponse{
+		Applied:   req.Apply,
+		Diffs:     []models.ApplicationDiff{},
+		Skipped:   []models.ReprocessSkipped{},
+		Conflicts: []models.ReprocessConflict{},
+	}
+	profiles := map[string]models.ImportProfile{}
+	// новые ключи, уже выданные заявкам в этом прогоне: две заявки не должны получить один
+	claimed := map[string]uuid.UUID{}
+
+	after := uuid.Nil
+	for {
+		page, err := s.repo.ListStoredApplications(ctx, importID, after, reprocessPageSize)
+		if err != nil {
+			return models.ReprocessResponse{}, err
+		}
+		if len(page) == 0 {
+			break
+		}
+		after = page[len(page)-1].Fields.ApplicationID
+
+		var (
+			changed []models.StoredApplication
+			parsed  []models.ApplicationFields
+		)
+		for _, a := range page {
+			res.Scanned++
+
+			fields, reason, err := s.reparse(ctx, profiles, a)
+			if err != nil {
+				return models.ReprocessResponse{}, err
+			}
+			if reason != "" {
+				res.SkippedCount++
+				if len(res.Skipped) < reprocessSkipLimit {
+					res.Skipped = append(res.Skipped, models.ReprocessSkipped{
+						ApplicationID: a.Fields.ApplicationID.String(),
+						ImportID:      a.ImportID.String(),
+						Reason:        reason,
+					})
+				}
+				continue
+			}
+
+			if len(diffApplicationFields(a.Fields, fields)) == 0 {
+				continue
+			}
+			changed = append(changed, a)
+			parsed = append(parsed, fields)
+		}
+
+		owners, err := s.keyOwners(ctx, changed, parsed)
+		if err != nil {
+			return models.ReprocessResponse{}, err
+		}
+
+		var updates []models.ApplicationFields
+		for i, a := range changed {
+			fields := parsed[i]
+			owner, taken := owners[fields.ExternalKey]
+			if !taken {
+				owner, taken = claimed[fields.ExternalKey]
+			}
+			if taken && owner != a.Fields.ApplicationID {
+				res.ConflictCount++
+				if len(res.Conflicts) < reprocessConflictLimit {
+					res.Conflicts = append(res.Conflicts, models.ReprocessConflict{
+						ApplicationID: a.Fields.ApplicationID.String(),
+						ImportID:      a.ImportID.String(),
+						DuplicateOf:   owner.String(),
+					})
+				}
+				continue
+			}
+			claimed[fields.ExternalKey] = a.Fields.ApplicationID
+
+			res.Changed++
+			if len(res.Diffs) < reprocessDiffLimit {
+				res.Diffs = append(res.Diffs, models.ApplicationDiff{
+					ApplicationID: a.Fields.ApplicationID.String(),
+					ImportID:      a.ImportID.String(),
+					Changes:       diffApplicationFields(a.Fields, fields),
+				})
+			}
+			updates = append(updates, fields)
+		}
+
+		if req.Apply && len(updates) > 0 {
+			n, err := s.repo.UpdateApplicationFields(ctx, updates)
+			if err != nil {
+				return models.ReprocessResponse{}, err
+			}
+			res.Updated += n
+		}
+	}
+	return res, nil
+}
+
+// keyOwners - владельцы новых ключей среди уже сохранённых заявок (ключ которых не меняется)
+func (s *Service) keyOwners(ctx context.Context, changed []models.StoredApplication, parsed []models.ApplicationFields) (map[string]uuid.UUID, error) {
+	var keys []string
+	for i, a := range changed {
+		if parsed[i].ExternalKey != a.Fields.ExternalKey {
+			keys = append(keys, parsed[i].ExternalKey)
+		}
+	}
+	return s.repo.ApplicationsByExternalKey(ctx, keys)
+}
+
+// reparse - raw_row через parseRow с профилем импорта заявки.
+// Непустой reason — строку больше не разобрать, заявку пропускаем.
+func (s *Service) reparse(ctx context.Context, profiles map[string]models.ImportProfile, a models.StoredApplication) (models.ApplicationFields, string, error) {
+	profile, ok := profiles[a.ProfileCode]
+	if !ok {
+		var err error
+		profile, err = s.resolveImportProfile(ctx, a.ProfileCode)
+		if IsImportProfileNotFound(err) {
+			return models.ApplicationFields{}, fmt.Sprintf("профиль импорта %q не найден", a.ProfileCode), nil
+		}
+		if err != nil {
+			return models.ApplicationFields{}, "", err
+		}
+		profiles[a.ProfileCode] = profile
+	}
+
+	// порядок столбцов в jsonb не сохраняется, а parseRow он и не нужен
+	header := make([]string, 0, len(a.RawRow))
+	for name := range a.RawRow {
+		header = append(header, name)
+	}
+	sort.Strings(header)
+	cells := make([]string, len(header))
+	for i, name := range header {
+		if v := a.RawRow[name]; v != nil {
+			cells[i] = fmt.Sprint(v)
+		}
+	}
+
+	col, err := matchColumns(profile, header)
+	if err != nil {
+		return models.ApplicationFields{}, err.Error(), nil
+	}
+	r, rowErrors, ok := parseRow(col, newDateParser(profile, a.Date1904), header, cells, 0)
+	if !ok {
+		return models.ApplicationFields{}, rowErrors[0].Message, nil
+	}
+
+	return models.ApplicationFields{
+		ApplicationID:   a.Fields.ApplicationID,
+		AppliedAt:       r.AppliedAt,
+		ResumeURL:       r.ResumeURL,
+		Priority1:       r.Priority1,
+		Priority2:       r.Priority2,
+		Course:          r.Course,
+		Specialty:       r.Specialty,
+		SpecialtyOther:  r.SpecialtyOther,
+		Schedule:        r.Schedule,
+		City:            r.City,
+		CityOther:       r.CityOther,
+		University:      r.University,
+		UniversityOther: r.UniversityOther,
+		Source:          r.Source,
+		ExternalKey:     repositories.ExternalKey(r),
+	}, "", nil
+}
+
+func diffApplicationFields(old, cur models.ApplicationFields) []models.FieldChange {
+	var out []models.FieldChange
+	if !old.AppliedAt.Equal(cur.AppliedAt) {
+		out = append(out, models.FieldChange{
+			Field: models.FieldAppliedAt,
+			Old:   old.AppliedAt.In(moscow).Format(time.RFC3339),
+			New:   cur.AppliedAt.In(moscow).Format(time.RFC3339),
+		})
+	}
+	texts := []struct {
+		field    string
+		old, cur string
+	}{
+		{models.FieldResumeURL, old.ResumeURL, cur.ResumeURL},
+		{models.FieldPriority1, old.Priority1, cur.Priority1},
+		{models.FieldPriority2, old.Priority2, cur.Priority2},
+		{models.FieldCourse, old.Course, cur.Course},
+		{models.FieldSpecialty, old.Specialty, cur.Specialty},
+		{models.FieldSpecialtyOther, old.SpecialtyOther, cur.SpecialtyOther},
+		{models.FieldSchedule, old.Schedule, cur.Schedule},
+		{models.FieldCity, old.City, cur.City},
+		{models.FieldCityOther, old.CityOther, cur.CityOther},
+		{models.FieldUniversity, old.University, cur.University},
+		{models.FieldUniversityOther, old.UniversityOther, cur.UniversityOther},
+		{models.FieldSource, old.Source, cur.Source},
+	}
+	for _, t := range texts {
+		if t.old != t.cur {
+			out = append(out, models.FieldChange{Field: t.field, Old: t.old, New: t.cur})
+		}
+	}
+	// ключ меняется вслед за датой и приоритетами, а сам по себе — у заявок,
+	// сохранённых с ключом от нормализованного телефона
+	if old.ExternalKey != cur.ExternalKey {
+		out = append(out, models.FieldChange{Field: "external_key", Old: old.ExternalKey, New: cur.ExternalKey})
+	}
+	return out
+}
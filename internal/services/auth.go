@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/repositories"
+)
+
+const (
+	apiKeyPrefix     = "x5_"
+	apiKeyBytes      = 32
+	apiKeyShownChars = len(apiKeyPrefix) + 8 // сколько символов ключа видно в списке
+)
+
+var (
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrAPIKeyNameRequired  = errors.New("name обязателен")
+	ErrAPIKeyExpiresInPast = errors.New("expires_at должен быть в будущем")
+)
+
+// IsAPIKey - по префиксу отличаем API ключ от JWT в заголовке Authorization
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, apiKeyPrefix)
+}
+
+// AuthenticateAPIKey - владелец действующего ключа
+func (s *Service) AuthenticateAPIKey(ctx context.Context, key string) (uuid.UUID, error) {
+	userID, err := s.repo.UseAPIKey(ctx, hashAPIKey(key))
+	if errors.Is(err, repositories.ErrAPIKeyNotFound) {
+		return uuid.Nil, ErrInvalidCredentials
+	}
+	return userID, err
+}
+
+// AuthenticateUser - пользователь из проверенного JWT: отключённый пользователь
+// теряет доступ сразу, не дожидаясь истечения токена
+func (s *Service) AuthenticateUser(ctx context.Context, userID uuid.UUID) error {
+	active, err := s.repo.IsActiveUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return ErrInvalidCredentials
+	}
+	return nil
+}
+
+// EnsureUser - пользователь по email, создаётся при первом обращении
+func (s *Service) EnsureUser(ctx context.Context, email, name string) (models.User, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return models.User{}, errors.New("email обязателен")
+	}
+	return s.repo.UpsertUser(ctx, email, strings.TrimSpace(name))
+}
+
+// IssueAPIKey - новый ключ пользователя; открытый ключ возвращается только здесь, в БД — sha256
+func (s *Service) IssueAPIKey(ctx context.Context, userID uuid.UUID, req models.IssueAPIKeyRequest) (models.IssuedAPIKey, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return models.IssuedAPIKey{}, ErrAPIKeyNameRequired
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return models.IssuedAPIKey{}, ErrAPIKeyExpiresInPast
+	}
+
+	b := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return models.IssuedAPIKey{}, fmt.Errorf("rand.Read: %w", err)
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	k, err := s.repo.InsertAPIKey(ctx, userID, name, key[:apiKeyShownChars], hashAPIKey(key), req.ExpiresAt)
+	if err != nil {
+		return models.IssuedAPIKey{}, err
+	}
+	return models.IssuedAPIKey{APIKey: k, Key: key}, nil
+}
+
+func (s *Service) ListAPIKeys(ctx context.Context, userID uuid.UUID) (models.ListAPIKeysResponse, error) {
+	items, err := s.repo.ListAPIKeys(ctx, userID)
+	if err != nil {
+		return models.ListAPIKeysResponse{}, err
+	}
+	return models.ListAPIKeysResponse{Items: items}, nil
+}
+
+func (s *Service) RevokeAPIKey(ctx context.Context, userID, keyID uuid.UUID) (models.APIKey, error) {
+	return s.repo.RevokeAPIKey(ctx, userID, keyID)
+}
+
+func IsAPIKeyNotFound(err error) bool {
+	return errors.Is(err, repositories.ErrAPIKeyNotFound)
+}
+
+// hashAPIKey - ключ случайный (256 бит), поэтому достаточно sha256 без соли
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
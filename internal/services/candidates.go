@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/repositories"
+	"github.com/kurushqosimi/x5-intern-hiring/pkg/emailaddr"
+	"github.com/kurushqosimi/x5-intern-hiring/pkg/phone"
+)
+
+var ErrSelfMerge = errors.New("source_candidate_id и target_candidate_id совпадают")
+
+func (s *Service) MergeCandidates(ctx context.Context, req models.MergeCandidatesRequest, mergedBy uuid.UUID) (models.MergeCandidatesResponse, error) {
+	sourceID, err := uuid.Parse(req.SourceCandidateID)
+	if err != nil {
+		return models.MergeCandidatesResponse{}, err
+	}
+	targetID, err := uuid.Parse(req.TargetCandidateID)
+	if err != nil {
+		return models.MergeCandidatesResponse{}, err
+	}
+	if sourceID == targetID {
+		return models.MergeCandidatesResponse{}, ErrSelfMerge
+	}
+
+	return s.repo.MergeCandidates(ctx, sourceID, targetID, mergedBy)
+}
+
+func (s *Service) ListDuplicateCandidates(ctx context.Context, limit, offset int) (models.ListDuplicatesResponse, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	items, err := s.repo.ListDuplicateCandidates(ctx, limit, offset)
+	if err != nil {
+		return models.ListDuplicatesResponse{}, err
+	}
+	return models.ListDuplicatesResponse{
+		Items:  items,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
+
+func IsCandidateNotFound(err error) bool {
+	return errors.Is(err, repositories.ErrCandidateNotFound)
+}
+
+var ErrUnsupportedContactType = errors.New("поддерживаются только phone и email")
+
+// BackfillContacts - пересчитывает normalized у уже сохранённых контактов:
+// телефоны приводятся к E.164, email проверяются и нормализуются с учётом почтовых сервисов
+func (s *Service) BackfillContacts(ctx context.Context, typ string, dryRun bool) (models.ContactBackfillResult, error) {
+	switch typ {
+	case "phone":
+		return s.repo.RenormalizeContacts(ctx, typ, phone.Normalize, dryRun)
+	case "email":
+		return s.repo.RenormalizeContacts(ctx, typ, normalizeEmail, dryRun)
+	default:
+		return models.ContactBackfillResult{}, ErrUnsupportedContactType
+	}
+}
+
+func normalizeEmail(s string) (string, error) {
+	if err := emailaddr.Validate(s); err != nil {
+		return "", err
+	}
+	return emailaddr.Normalize(s), nil
+}
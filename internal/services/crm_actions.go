@@ -7,7 +7,7 @@ import (
 	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
 )
 
-func (s *Service) QueueToCRM(ctx context.Context, req models.BulkCRMActionRequest) (models.BulkCRMActionResponse, error) {
+func (s *Service) QueueToCRM(ctx context.Context, req models.BulkCRMActionRequest, by uuid.UUID) (models.BulkCRMActionResponse, error) {
 	ids := make([]uuid.UUID, 0, len(req.ApplicationIDs))
 	for _, x := range req.ApplicationIDs {
 		id, err := uuid.Parse(x)
@@ -16,5 +16,5 @@ func (s *Service) QueueToCRM(ctx context.Context, req models.BulkCRMActionReques
 		}
 		ids = append(ids, id)
 	}
-	return s.repo.QueueCRM(ctx, ids)
+	return s.repo.QueueCRM(ctx, ids, by)
 }
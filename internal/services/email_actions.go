@@ -21,7 +21,7 @@ func parseUUIDs(ids []string) ([]uuid.UUID, error) {
 	return out, nil
 }
 
-func (s *Service) Invite(ctx context.Context, req models.BulkEmailActionRequest) (models.BulkEmailActionResponse, error) {
+func (s *Service) Invite(ctx context.Context, req models.BulkEmailActionRequest, by uuid.UUID) (models.BulkEmailActionResponse, error) {
 	if req.TemplateCode == "" {
 		req.TemplateCode = "intern_invite_v1"
 	}
@@ -29,10 +29,10 @@ func (s *Service) Invite(ctx context.Context, req models.BulkEmailActionRequest)
 	if err != nil {
 		return models.BulkEmailActionResponse{}, err
 	}
-	return s.repo.QueueInviteEmails(ctx, ids, req.TemplateCode)
+	return s.repo.QueueInviteEmails(ctx, ids, req.TemplateCode, by)
 }
 
-func (s *Service) Reject(ctx context.Context, req models.BulkEmailActionRequest) (models.BulkEmailActionResponse, error) {
+func (s *Service) Reject(ctx context.Context, req models.BulkEmailActionRequest, by uuid.UUID) (models.BulkEmailActionResponse, error) {
 	if req.TemplateCode == "" {
 		req.TemplateCode = "intern_reject_v1"
 	}
@@ -40,7 +40,7 @@ func (s *Service) Reject(ctx context.Context, req models.BulkEmailActionRequest)
 	if err != nil {
 		return models.BulkEmailActionResponse{}, err
 	}
-	return s.repo.QueueRejectEmails(ctx, ids, req.TemplateCode, req.StatusReason)
+	return s.repo.QueueRejectEmails(ctx, ids, req.TemplateCode, req.StatusReason, by)
 }
 
 func IsTemplateNotFound(err error) bool {
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+)
+
+var ErrNothingToRequeue = errors.New("email_ids или all обязательны")
+
+func (s *Service) ListDeadEmails(ctx context.Context, limit, offset int, createdBy string) (models.ListDeadEmailsResponse, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	items, total, err := s.repo.ListDeadEmails(ctx, limit, offset, createdBy)
+	if err != nil {
+		return models.ListDeadEmailsResponse{}, err
+	}
+	return models.ListDeadEmailsResponse{
+		Items:  items,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
+
+func (s *Service) RequeueDeadEmails(ctx context.Context, req models.RequeueEmailsRequest, requeuedBy uuid.UUID) (models.RequeueEmailsResponse, error) {
+	if !req.All && len(req.EmailIDs) == 0 {
+		return models.RequeueEmailsResponse{}, ErrNothingToRequeue
+	}
+
+	ids, err := parseUUIDs(req.EmailIDs)
+	if err != nil {
+		return models.RequeueEmailsResponse{}, err
+	}
+	if req.All {
+		ids = nil
+	}
+
+	n, err := s.repo.RequeueDeadEmails(ctx, ids, requeuedBy)
+	if err != nil {
+		return models.RequeueEmailsResponse{}, err
+	}
+
+	res := models.RequeueEmailsResponse{Requeued: n}
+	if !req.All {
+		res.Skipped = len(ids) - n
+	}
+	return res, nil
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kurushqosimi/x5-intern-hiring/internal/custom_errors"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"golang.org/x/text/encoding/charmap"
+)
+
+var (
+	zipMagic = []byte("PK\x03\x04")
+	// старый бинарный .xls (OLE2) — не поддерживаем, просим пересохранить
+	oleMagic = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+	utf8BOM  = []byte{0xEF, 0xBB, 0xBF}
+)
+
+// detectFormat - формат по расширению, а если оно ничего не говорит — по первым байтам файла.
+// Пустая строка — формат не поддерживается.
+func detectFormat(fileName string, head []byte) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".xlsx", ".xlsm":
+		return models.FormatXLSX
+	case ".csv":
+		return models.FormatCSV
+	case ".tsv", ".tab":
+		return models.FormatTSV
+	case ".xls":
+		return ""
+	}
+
+	switch {
+	case bytes.HasPrefix(head, zipMagic):
+		return models.FormatXLSX
+	case bytes.HasPrefix(head, oleMagic):
+		return ""
+	default:
+		return models.FormatCSV
+	}
+}
+
+// sniffDelimiter - разделитель, который чаще всего встречается в первой строке вне кавычек
+func sniffDelimiter(text []byte) rune {
+	line := text
+	if i := bytes.IndexByte(text, '\n'); i >= 0 {
+		line = text[:i]
+	}
+
+	counts := map[rune]int{}
+	inQuotes := false
+	for _, ch := range string(line) {
+		switch ch {
+		case '"':
+			inQuotes = !inQuotes
+		case ',', ';', '\t', '|':
+			if !inQuotes {
+				counts[ch]++
+			}
+		}
+	}
+
+	best, bestCount := ',', 0
+	for _, d := range []rune{',', ';', '\t', '|'} {
+		if counts[d] > bestCount {
+			best, bestCount = d, counts[d]
+		}
+	}
+	return best
+}
+
+// сколько байт начала файла смотрим, чтобы определить кодировку и разделитель
+const csvSampleSize = 64 << 10
+
+// csvRows - построчное чтение csv/tsv с диска.
+// Формы отдают UTF-8 (иногда с BOM), Excel под Windows сохраняет CSV в Windows-1251 —
+// кодировка определяется по началу файла.
+type csvRows struct {
+	f   *os.File
+	r   *csv.Reader
+	cur []string
+	err error
+}
+
+func openCSVRows(path, format string) (*csvRows, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, custom_errors.ErrFailedToOpenFile)
+	}
+
+	br := bufio.NewReaderSize(f, csvSampleSize)
+	sample, err := br.Peek(csvSampleSize)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		_ = f.Close()
+		return nil, fmt.Errorf("%v: %w", err, custom_errors.ErrFailedToReadFile)
+	}
+	truncated := len(sample) == csvSampleSize
+	if bytes.HasPrefix(sample, utf8BOM) {
+		_, _ = br.Discard(len(utf8BOM))
+		sample = sample[len(utf8BOM):]
+	}
+
+	var (
+		text             = sample
+		reader io.Reader = br
+	)
+	if !validUTF8Sample(sample, truncated) {
+		text, err = charmap.Windows1251.NewDecoder().Bytes(sample)
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("%w: unknown encoding: %v", custom_errors.ErrInvalidCSV, err)
+		}
+		reader = charmap.Windows1251.NewDecoder().Reader(br)
+	}
+
+	delim := '\t'
+	if format != models.FormatTSV {
+		delim = sniffDelimiter(text)
+	}
+
+	r := csv.NewReader(reader)
+	r.Comma = delim
+	r.FieldsPerRecord = -1 // строки разной длины — норма для выгрузок
+	r.LazyQuotes = true
+	return &csvRows{f: f, r: r}, nil
+}
+
+func (c *csvRows) Next() bool {
+	if c.err != nil {
+		return false
+	}
+	rec, err := c.r.Read()
+	if errors.Is(err, io.EOF) {
+		return false
+	}
+	if err != nil {
+		c.err = fmt.Errorf("%w: %v", custom_errors.ErrInvalidCSV, err)
+		return false
+	}
+	c.cur = rec
+	return true
+}
+
+func (c *csvRows) Columns() ([]string, error) {
+	return c.cur, nil
+}
+
+func (c *csvRows) Error() error {
+	return c.err
+}
+
+func (c *csvRows) Close() error {
+	return c.f.Close()
+}
+
+// validUTF8Sample - начало файла в UTF-8; последний символ выборки мог оказаться разрезан её границей
+func validUTF8Sample(b []byte, truncated bool) bool {
+	if utf8.Valid(b) {
+		return true
+	}
+	if !truncated {
+		return false
+	}
+	for i := 1; i < utf8.UTFMax && i <= len(b); i++ {
+		if utf8.RuneStart(b[len(b)-i]) {
+			return utf8.Valid(b[:len(b)-i])
+		}
+	}
+	return false
+}
+
+// countLines - число строк файла без чтения его целиком в память
+func countLines(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var (
+		buf  = make([]byte, 32<<10)
+		n    int
+		last byte
+	)
+	for {
+		k, err := f.Read(buf)
+		if k > 0 {
+			n += bytes.Count(buf[:k], []byte{'\n'})
+			last = buf[k-1]
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	if last != 0 && last != '\n' {
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// даты в файлах — московское время; в Москве нет перехода на летнее время с 2014 года,
+// поэтому фиксированное смещение и не нужен tzdata в образе.
+// Раньше даты разбирались в time.Local: на сервере не в MSK у заявок, загруженных до этого,
+// applied_at (а с ним и external_key) сдвинуты на разницу поясов. Их пересчитывает
+// POST /applications/reprocess {"all":true,"apply":true} — сначала без apply, чтобы увидеть сдвиги.
+var moscow = time.FixedZone("MSK", 3*60*60)
+
+// встроенные форматы даты подачи; американские (01/02/2006) не встроены — 03/04 путается
+// с днём и месяцем, профиль с такими файлами добавляет их в DateLayouts
+var defaultDateLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02.01.2006 15:04:05",
+	"02.01.2006 15:04",
+	"02.01.2006",
+	"2.1.2006 15:04",
+	"2.1.2006",
+	"02.01.06",
+	time.RFC3339,
+}
+
+// серийные номера Excel, которые считаем датой: 1970-01-01 .. 9999-12-31
+const (
+	minExcelSerial = 25569
+	maxExcelSerial = 2958465
+)
+
+// dateParser - разбор дат с учётом форматов профиля и настроек книги
+type dateParser struct {
+	layouts  []string // сначала форматы профиля, потом встроенные
+	date1904 bool     // книга Excel с системой дат 1904 (старые файлы с Mac)
+}
+
+func newDateParser(profile models.ImportProfile, date1904 bool) dateParser {
+	layouts := make([]string, 0, len(profile.DateLayouts)+len(defaultDateLayouts))
+	layouts = append(layouts, profile.DateLayouts...)
+	layouts = append(layouts, defaultDateLayouts...)
+	return dateParser{layouts: layouts, date1904: date1904}
+}
+
+// parse - текстовая дата по одному из форматов или серийный номер Excel
+// (ячейка с форматом даты читается как число). Время без зоны считается московским.
+func (p dateParser) parse(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, errors.New("empty")
+	}
+
+	if t, ok := p.parseSerial(s); ok {
+		return t, nil
+	}
+
+	for _, l := range p.layouts {
+		if t, err := time.ParseInLocation(l, s, moscow); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("неподдерживаемый формат даты: %q", s)
+}
+
+func (p dateParser) parseSerial(s string) (time.Time, bool) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < minExcelSerial || v > maxExcelSerial {
+		return time.Time{}, false
+	}
+	t, err := excelize.ExcelDateToTime(v, p.date1904)
+	if err != nil {
+		return time.Time{}, false
+	}
+	// в серийном номере нет зоны — это московские дата и время
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, moscow)
+	return t, true
+}
+
+// year - год рождения: число ("2001", "2001 г.") или дата, из которой берётся год
+func (p dateParser) year(s string) (int, error) {
+	if t, err := p.parse(s); err == nil {
+		return t.Year(), nil
+	}
+	y, err := parseYear(s)
+	if err != nil {
+		return 0, err
+	}
+	if y < 1000 || y > 9999 {
+		return 0, fmt.Errorf("неверный год: %q", s)
+	}
+	return y, nil
+}
+
+// validateDateLayout - формат должен содержать хотя бы год, месяц и день
+func validateDateLayout(l string) error {
+	ref := time.Date(2031, time.November, 27, 0, 0, 0, 0, time.UTC)
+	t, err := time.Parse(l, ref.Format(l))
+	if err != nil {
+		return err
+	}
+	if !t.Equal(ref) {
+		return errors.New("формат должен содержать год, месяц и день (например 02/01/2006)")
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+)
+
+func TestDateParserParse(t *testing.T) {
+	p := newDateParser(models.ImportProfile{}, false)
+	cases := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2025-03-04 10:30", time.Date(2025, 3, 4, 10, 30, 0, 0, moscow)},
+		{"04.03.2025", time.Date(2025, 3, 4, 0, 0, 0, 0, moscow)},
+		{"4.3.2025 9:05", time.Date(2025, 3, 4, 9, 5, 0, 0, moscow)},
+		{"2025-03-04T10:30:00Z", time.Date(2025, 3, 4, 13, 30, 0, 0, moscow)},
+		{"45720.4375", time.Date(2025, 3, 4, 10, 30, 0, 0, moscow)},
+	}
+	for _, c := range cases {
+		got, err := p.parse(c.in)
+		if err != nil {
+			t.Errorf("parse(%q): %v", c.in, err)
+			continue
+		}
+		if !got.Equal(c.want) {
+			t.Errorf("parse(%q) = %v, want %v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestDateParserUSLayoutsOptIn(t *testing.T) {
+	if _, err := newDateParser(models.ImportProfile{}, false).parse("03/04/2025"); err == nil {
+		t.Fatal("month-first date parsed without profile layout")
+	}
+
+	p := newDateParser(models.ImportProfile{DateLayouts: []string{"01/02/2006"}}, false)
+	got, err := p.parse("03/04/2025")
+	if err != nil {
+		t.Fatalf("parse with profile layout: %v", err)
+	}
+	if want := time.Date(2025, 3, 4, 0, 0, 0, 0, moscow); !got.Equal(want) {
+		t.Errorf("parse = %v, want %v", got, want)
+	}
+}
+
+func TestDateParser1904(t *testing.T) {
+	// один и тот же серийный номер в книгах 1900 и 1904 отличается на 1462 дня
+	d1900, err := newDateParser(models.ImportProfile{}, false).parse("45720")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d1904, err := newDateParser(models.ImportProfile{}, true).parse("45720")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := d1904.Sub(d1900); diff != 1462*24*time.Hour {
+		t.Errorf("1904 - 1900 = %v, want 1462 days", diff)
+	}
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	errorColumnTitle = "Ошибка"
+	csvReportSheet   = "Ошибки" // лист отчёта для csv, у которого своих листов нет
+)
+
+// ImportErrorsXLSX - отклонённые строки импорта в исходном виде и столбец с ошибкой:
+// рекрутер исправляет их и загружает файл заново. Листы — как в исходной книге.
+func (s *Service) ImportErrorsXLSX(ctx context.Context, importID uuid.UUID) (string, *bytes.Buffer, error) {
+	rep, err := s.repo.GetImportErrorReport(ctx, importID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// профиль нужен только чтобы найти столбец даты заявки; если его удалили — отчёт без этого
+	var profile *models.ImportProfile
+	if p, err := s.resolveImportProfile(ctx, rep.ProfileCode); err == nil {
+		profile = &p
+	}
+
+	f, err := errorReportFile(rep, profile)
+	if err != nil {
+		return "", nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return "", nil, err
+	}
+	return reportFileName(rep.FileName), buf, nil
+}
+
+// errorReportFile - книга отчёта: по листу на каждый лист исходного файла с отклонёнными строками
+func errorReportFile(rep models.ImportErrorReport, profile *models.ImportProfile) (f *excelize.File, err error) {
+	f = excelize.NewFile()
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+		}
+	}()
+
+	// строки отсортированы по листу: каждому листу — свой лист отчёта
+	first := true
+	for start := 0; start < len(rep.Rows) || first; {
+		sheet := ""
+		if start < len(rep.Rows) {
+			sheet = rep.Rows[start].Sheet
+		}
+		end := start
+		for end < len(rep.Rows) && rep.Rows[end].Sheet == sheet {
+			end++
+		}
+
+		name := sheet
+		if name == "" {
+			name = csvReportSheet
+		}
+		if first {
+			if err = f.SetSheetName(f.GetSheetName(0), name); err != nil {
+				return nil, err
+			}
+			first = false
+		} else if _, err = f.NewSheet(name); err != nil {
+			return nil, err
+		}
+
+		if err = writeErrorSheet(f, name, rep.Headers[sheet], rep.Rows[start:end], profile, rep.Date1904); err != nil {
+			return nil, err
+		}
+		start = end
+	}
+	return f, nil
+}
+
+// writeErrorSheet - заголовок файла + столбец "Ошибка"; несколько ошибок одной строки — через "; "
+func writeErrorSheet(f *excelize.File, name string, header []string, rows []models.ImportRowError, profile *models.ImportProfile, date1904 bool) error {
+	sw, err := f.NewStreamWriter(name)
+	if err != nil {
+		return err
+	}
+
+	width := len(header)
+	for _, e := range rows {
+		if len(e.Cells) > width {
+			width = len(e.Cells)
+		}
+	}
+
+	// серийный номер даты из xlsx возвращаем датой, иначе в отчёте будет "45717.375"
+	dateCol := -1
+	var dates dateParser
+	if profile != nil {
+		if col, err := matchColumns(*profile, header); err == nil {
+			if idx, ok := col[models.FieldAppliedAt]; ok {
+				dateCol = idx
+			}
+		}
+		dates = newDateParser(*profile, date1904)
+	}
+
+	title := make([]interface{}, width+1)
+	for i := range title[:width] {
+		if i < len(header) {
+			title[i] = header[i]
+		}
+	}
+	title[width] = errorColumnTitle
+	if err = sw.SetRow("A1", title); err != nil {
+		return err
+	}
+
+	line := 2
+	for i := 0; i < len(rows); {
+		e := rows[i]
+		messages := []string{e.Message}
+		for i++; i < len(rows) && rows[i].Row == e.Row; i++ {
+			messages = append(messages, rows[i].Message)
+		}
+
+		values := make([]interface{}, width+1)
+		for j := 0; j < width; j++ {
+			if j >= len(e.Cells) {
+				continue
+			}
+			v := e.Cells[j]
+			if j == dateCol {
+				if t, ok := dates.parseSerial(v); ok {
+					v = t.Format("02.01.2006 15:04")
+				}
+			}
+			values[j] = v
+		}
+		values[width] = strings.Join(messages, "; ")
+
+		cell, err := excelize.CoordinatesToCellName(1, line)
+		if err != nil {
+			return err
+		}
+		if err = sw.SetRow(cell, values); err != nil {
+			return err
+		}
+		line++
+	}
+	return sw.Flush()
+}
+
+// reportFileName - "отклики.csv" -> "отклики_ошибки.xlsx"
+func reportFileName(fileName string) string {
+	base := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	if base == "" {
+		base = "import"
+	}
+	return base + "_ошибки.xlsx"
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+)
+
+func sampleCells(i int, appliedAt string) []string {
+	row := sampleRow(i)
+	cells := make([]string, len(row))
+	for j, v := range row {
+		cells[j] = fmt.Sprint(v)
+	}
+	cells[len(cells)-1] = appliedAt
+	return cells
+}
+
+func TestErrorReportFile(t *testing.T) {
+	header := make([]string, len(sampleHeader))
+	for i, v := range sampleHeader {
+		header[i] = fmt.Sprint(v)
+	}
+	spring, autumn := sampleCells(1, "45720.4375"), sampleCells(2, "04.03.2025 11:00")
+	rep := models.ImportErrorReport{
+		FileName:    "отклики.xlsx",
+		ProfileCode: models.DefaultImportProfileCode,
+		Headers:     map[string][]string{"Весна": header, "Осень": header},
+		Rows: []models.ImportRowError{
+			{Sheet: "Весна", Row: 2, Message: "некорректный email", Rejected: true, Cells: spring},
+			{Sheet: "Весна", Row: 2, Message: "некорректный телефон", Rejected: true, Cells: spring},
+			{Sheet: "Осень", Row: 5, Message: "не указана фамилия", Rejected: true, Cells: autumn},
+		},
+	}
+	profile := models.DefaultImportProfile()
+
+	f, err := errorReportFile(rep, &profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if got := f.GetSheetList(); !reflect.DeepEqual(got, []string{"Весна", "Осень"}) {
+		t.Fatalf("sheets = %v", got)
+	}
+
+	last := len(header)
+	cases := []struct {
+		sheet   string
+		applied string
+		errors  string
+	}{
+		{"Весна", "04.03.2025 10:30", "некорректный email; некорректный телефон"},
+		{"Осень", "04.03.2025 11:00", "не указана фамилия"},
+	}
+	for _, c := range cases {
+		rows, err := f.GetRows(c.sheet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 2 {
+			t.Fatalf("%s: rows = %d, want header and one row", c.sheet, len(rows))
+		}
+		if rows[0][last] != errorColumnTitle {
+			t.Errorf("%s: error column = %q", c.sheet, rows[0][last])
+		}
+		if got := rows[1][last-1]; got != c.applied {
+			t.Errorf("%s: applied at = %q, want %q", c.sheet, got, c.applied)
+		}
+		if got := rows[1][last]; got != c.errors {
+			t.Errorf("%s: errors = %q, want %q", c.sheet, got, c.errors)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kurushqosimi/x5-intern-hiring/internal/custom_errors"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/repositories"
+)
+
+// resolveImportProfile - профиль по коду; пустой код — профиль по умолчанию из БД или встроенный
+func (s *Service) resolveImportProfile(ctx context.Context, code string) (models.ImportProfile, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		p, err := s.repo.GetDefaultImportProfile(ctx)
+		if errors.Is(err, repositories.ErrImportProfileNotFound) {
+			return models.DefaultImportProfile(), nil
+		}
+		return p, err
+	}
+
+	if code == models.IntakeProfileCode {
+		return models.IntakeProfile(), nil
+	}
+
+	p, err := s.repo.GetImportProfileByCode(ctx, code)
+	if errors.Is(err, repositories.ErrImportProfileNotFound) && code == models.DefaultImportProfileCode {
+		return models.DefaultImportProfile(), nil
+	}
+	return p, err
+}
+
+func (s *Service) ListImportProfiles(ctx context.Context) (models.ListImportProfilesResponse, error) {
+	items, err := s.repo.ListImportProfiles(ctx)
+	if err != nil {
+		return models.ListImportProfilesResponse{}, err
+	}
+
+	// встроенный профиль показываем, если его не переопределили в БД
+	hasDefault := false
+	for _, p := range items {
+		if p.Code == models.DefaultImportProfileCode {
+			hasDefault = true
+		}
+	}
+	if !hasDefault {
+		items = append([]models.ImportProfile{models.DefaultImportProfile()}, items...)
+	}
+	return models.ListImportProfilesResponse{Items: items}, nil
+}
+
+func (s *Service) GetImportProfile(ctx context.Context, code string) (models.ImportProfile, error) {
+	if strings.TrimSpace(code) == "" {
+		return models.ImportProfile{}, repositories.ErrImportProfileNotFound
+	}
+	return s.resolveImportProfile(ctx, code)
+}
+
+func (s *Service) SaveImportProfile(ctx context.Context, p models.ImportProfile) (models.ImportProfile, error) {
+	if err := validateImportProfile(&p); err != nil {
+		return models.ImportProfile{}, err
+	}
+	return s.repo.UpsertImportProfile(ctx, p)
+}
+
+func IsImportProfileNotFound(err error) bool {
+	return errors.Is(err, repositories.ErrImportProfileNotFound)
+}
+
+func validateImportProfile(p *models.ImportProfile) error {
+	p.Code = strings.TrimSpace(p.Code)
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Code == "" {
+		return fmt.Errorf("%w: code обязателен", custom_errors.ErrInvalidImportProfile)
+	}
+	if p.Name == "" {
+		p.Name = p.Code
+	}
+	if len(p.Columns) == 0 {
+		return fmt.Errorf("%w: columns обязателен", custom_errors.ErrInvalidImportProfile)
+	}
+
+	known := make(map[string]struct{}, len(models.ImportFields))
+	for _, f := range models.ImportFields {
+		known[f] = struct{}{}
+	}
+
+	// один и тот же заголовок не может означать два поля
+	seen := map[string]string{}
+	for field, m := range p.Columns {
+		if _, ok := known[field]; !ok {
+			return fmt.Errorf("%w: неизвестное поле %q", custom_errors.ErrInvalidImportProfile, field)
+		}
+
+		aliases := make([]string, 0, len(m.Aliases))
+		for _, a := range m.Aliases {
+			a = strings.TrimSpace(a)
+			if a == "" {
+				continue
+			}
+			norm := normalizeHeader(a)
+			if other, ok := seen[norm]; ok && other != field {
+				return fmt.Errorf("%w: заголовок %q указан у полей %s и %s", custom_errors.ErrInvalidImportProfile, a, other, field)
+			}
+			seen[norm] = field
+			aliases = append(aliases, a)
+		}
+		if len(aliases) == 0 {
+			return fmt.Errorf("%w: у поля %s нет заголовков", custom_errors.ErrInvalidImportProfile, field)
+		}
+		m.Aliases = aliases
+		p.Columns[field] = m
+	}
+
+	if _, ok := p.Columns[models.FieldAppliedAt]; !ok {
+		return fmt.Errorf("%w: нужен столбец для %s", custom_errors.ErrInvalidImportProfile, models.FieldAppliedAt)
+	}
+
+	layouts := make([]string, 0, len(p.DateLayouts))
+	for _, l := range p.DateLayouts {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			continue
+		}
+		if err := validateDateLayout(l); err != nil {
+			return fmt.Errorf("%w: формат даты %q: %v", custom_errors.ErrInvalidImportProfile, l, err)
+		}
+		layouts = append(layouts, l)
+	}
+	p.DateLayouts = layouts
+	return nil
+}
+
+// normalizeHeader - сравнение заголовков без учёта регистра, ё/е и лишних пробелов
+func normalizeHeader(h string) string {
+	h = strings.ToLower(strings.Join(strings.Fields(h), " "))
+	return strings.ReplaceAll(h, "ё", "е")
+}
+
+// columnIndex - поле ParsedRow -> индекс столбца в файле
+type columnIndex map[string]int
+
+// matchColumns - сопоставляет заголовок файла с профилем.
+// Если нет обязательных столбцов — ошибка со списком всех недостающих.
+func matchColumns(profile models.ImportProfile, header []string) (columnIndex, error) {
+	byHeader := make(map[string]int, len(header))
+	for i, h := range header {
+		norm := normalizeHeader(h)
+		if norm == "" {
+			continue
+		}
+		if _, ok := byHeader[norm]; !ok {
+			byHeader[norm] = i
+		}
+	}
+
+	col := make(columnIndex, len(profile.Columns))
+	var missing []string
+	for _, field := range models.ImportFields {
+		m, ok := profile.Columns[field]
+		if !ok {
+			continue
+		}
+		found := false
+		for _, a := range m.Aliases {
+			if idx, ok := byHeader[normalizeHeader(a)]; ok {
+				col[field] = idx
+				found = true
+				break
+			}
+		}
+		if !found && m.Required && len(m.Aliases) > 0 {
+			missing = append(missing, m.Aliases[0])
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, &custom_errors.MissingColumnsError{Columns: missing}
+	}
+	return col, nil
+}
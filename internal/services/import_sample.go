@@ -0,0 +1,59 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// sampleHeader - заголовок выгрузки формы откликов (профиль по умолчанию)
+var sampleHeader = []interface{}{
+	"Фамилия", "Имя", "Почта", "Телефон", "ТГ", "Первый приоритет", "Курс",
+	"Специальность", "Город", "Год рождения", "ВУЗ", "Дата заявки",
+}
+
+// sampleRow - i-я (с 1) строка выгрузки с уникальными контактами и датой подачи
+func sampleRow(i int) []interface{} {
+	applied := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	return []interface{}{
+		fmt.Sprintf("Иванов%d", i),
+		"Иван",
+		fmt.Sprintf("student%d@example.ru", i),
+		fmt.Sprintf("+7 (9%02d) %03d-%02d-%02d", i%100, (i/100)%1000, (i/100000)%100, i%97),
+		fmt.Sprintf("@student%d", i),
+		"Аналитика",
+		fmt.Sprintf("%d", i%6+1),
+		"Прикладная информатика",
+		"Москва",
+		fmt.Sprintf("%d", 1998+i%8),
+		"МГУ",
+		applied.Add(time.Duration(i) * time.Minute).Format("02.01.2006 15:04"),
+	}
+}
+
+// WriteSampleXLSX - книга на rows строк в формате выгрузки формы откликов для замеров
+// (cmd/import-bench, BenchmarkScanFile). Пишется через StreamWriter, чтобы генератор
+// сам не держал файл в памяти
+func WriteSampleXLSX(path string, rows int) error {
+	f := excelize.NewFile()
+	defer func() { _ = f.Close() }()
+
+	sw, err := f.NewStreamWriter("Sheet1")
+	if err != nil {
+		return err
+	}
+	if err = sw.SetRow("A1", sampleHeader); err != nil {
+		return err
+	}
+	for i := 1; i <= rows; i++ {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err = sw.SetRow(cell, sampleRow(i)); err != nil {
+			return err
+		}
+	}
+	if err = sw.Flush(); err != nil {
+		return err
+	}
+	return f.SaveAs(path)
+}
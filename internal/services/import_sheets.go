@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"os"
+	"strings"
+
+	"github.com/kurushqosimi/x5-intern-hiring/internal/custom_errors"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+)
+
+// ListSheets - листы загруженной книги: заголовок, число строк и подходит ли лист под профиль
+func (s *Service) ListSheets(ctx context.Context, fileHeader *multipart.FileHeader, profileCode string) (models.ListSheetsResponse, error) {
+	metadata, path, err := s.readFile(fileHeader, models.FormatXLSX)
+	if err != nil {
+		return models.ListSheetsResponse{}, err
+	}
+	defer func() { _ = os.Remove(path) }()
+
+	profile, err := s.resolveImportProfile(ctx, profileCode)
+	if err != nil {
+		return models.ListSheetsResponse{}, err
+	}
+
+	t, err := openTable(path, models.FormatXLSX)
+	if err != nil {
+		return models.ListSheetsResponse{}, err
+	}
+	defer func() { _ = t.Close() }()
+
+	sheets, err := sheetInfos(t, profile)
+	if err != nil {
+		return models.ListSheetsResponse{}, err
+	}
+	return models.ListSheetsResponse{
+		FileName: metadata.FileName,
+		Profile:  profile.Code,
+		Sheets:   sheets,
+	}, nil
+}
+
+// sheetInfos - по каждому листу книги: заголовок, число строк и совпадение с профилем
+func sheetInfos(t *tableFile, profile models.ImportProfile) ([]models.SheetInfo, error) {
+	names := t.sheetNames()
+	if len(names) == 0 {
+		return nil, custom_errors.ErrNoXLSXSheets
+	}
+
+	out := make([]models.SheetInfo, 0, len(names))
+	for i, name := range names {
+		info := models.SheetInfo{Index: i, Name: name, Header: []string{}}
+
+		header, rows, err := sheetSummary(t, name)
+		if err != nil && !errors.Is(err, custom_errors.ErrNoXLSXData) {
+			return nil, err
+		}
+		if header != nil {
+			info.Header = header
+		}
+		info.Rows = rows
+
+		if err == nil {
+			_, mErr := matchColumns(profile, header)
+			var missing *custom_errors.MissingColumnsError
+			switch {
+			case mErr == nil:
+				info.Matches = true
+			case errors.As(mErr, &missing):
+				info.MissingColumns = missing.Columns
+			default:
+				return nil, mErr
+			}
+		}
+		out = append(out, info)
+	}
+	return out, nil
+}
+
+// resolveSheets - какие листы импортировать:
+// явно перечисленные (каждый должен существовать и подходить под профиль),
+// при AllSheets — все листы с подходящим заголовком, иначе — первый лист
+func resolveSheets(t *tableFile, profile models.ImportProfile, opts models.ImportOptions) ([]string, error) {
+	names := t.sheetNames()
+	if len(names) == 0 {
+		return nil, custom_errors.ErrNoXLSXSheets
+	}
+
+	switch {
+	case opts.AllSheets:
+		var out []string
+		for _, name := range names {
+			header, err := t.header(name)
+			if errors.Is(err, custom_errors.ErrNoXLSXData) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if _, err = matchColumns(profile, header); err == nil {
+				out = append(out, name)
+			}
+		}
+		if len(out) == 0 {
+			return nil, custom_errors.ErrNoMatchingSheets
+		}
+		return out, nil
+
+	case len(opts.Sheets) > 0:
+		exists := make(map[string]struct{}, len(names))
+		for _, name := range names {
+			exists[name] = struct{}{}
+		}
+
+		out := make([]string, 0, len(opts.Sheets))
+		seen := map[string]struct{}{}
+		for _, name := range opts.Sheets {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+
+			if _, ok := exists[name]; !ok {
+				return nil, &custom_errors.SheetNotFoundError{Sheet: name}
+			}
+			header, err := t.header(name)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", err, name)
+			}
+			if _, err = matchColumns(profile, header); err != nil {
+				var missing *custom_errors.MissingColumnsError
+				if errors.As(err, &missing) {
+					missing.Sheet = name
+				}
+				return nil, err
+			}
+			out = append(out, name)
+		}
+		if len(out) == 0 {
+			return nil, custom_errors.ErrNoXLSXSheets
+		}
+		return out, nil
+
+	default:
+		header, err := t.header(names[0])
+		if err != nil {
+			return nil, err
+		}
+		if _, err = matchColumns(profile, header); err != nil {
+			return nil, err
+		}
+		return []string{names[0]}, nil
+	}
+}
+
+// sheetSummary - заголовок и число непустых строк данных
+func sheetSummary(t *tableFile, sheet string) (header []string, count int, err error) {
+	rows, err := t.rows(sheet)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	if !rows.Next() {
+		return nil, 0, custom_errors.ErrNoXLSXData
+	}
+	if header, err = rows.Columns(); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", custom_errors.ErrInvalidXLSX, err)
+	}
+	for rows.Next() {
+		r, err := rows.Columns()
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: %v", custom_errors.ErrInvalidXLSX, err)
+		}
+		if !allEmpty(r) {
+			count++
+		}
+	}
+	return header, count, rows.Error()
+}
+
+// sheetStats - итоги по листам в порядке выбора; для csv листов нет
+func sheetStats(sheets []string, scanned scanResult, stats models.InsertStats) []models.SheetStats {
+	if len(sheets) == 0 {
+		return nil
+	}
+
+	idx := make(map[string]int, len(sheets))
+	out := make([]models.SheetStats, len(sheets))
+	for i, name := range sheets {
+		idx[name] = i
+		out[i] = models.SheetStats{
+			Sheet:        name,
+			TotalRows:    scanned.bySheet[name],
+			InsertedRows: stats.BySheet[name].Inserted,
+			SkippedRows:  stats.BySheet[name].Skipped,
+			FailedRows:   stats.BySheet[name].Failed,
+		}
+	}
+	for _, e := range scanned.errors {
+		if i, ok := idx[e.Sheet]; ok {
+			out[i].ErrorsCount++
+		}
+	}
+	return out
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kurushqosimi/x5-intern-hiring/internal/custom_errors"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// openSheetsWorkbook - книга из четырёх листов: два подходят под профиль по умолчанию,
+// у "Сводка" другой заголовок, "Пусто" без строк
+func openSheetsWorkbook(t *testing.T) *tableFile {
+	t.Helper()
+	f := excelize.NewFile()
+	defer func() { _ = f.Close() }()
+
+	setRows := func(sheet string, rows ...[]interface{}) {
+		for i, r := range rows {
+			cell, _ := excelize.CoordinatesToCellName(1, i+1)
+			if err := f.SetSheetRow(sheet, cell, &r); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := f.SetSheetName("Sheet1", "Весна"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Сводка", "Пусто", "Осень"} {
+		if _, err := f.NewSheet(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setRows("Весна", sampleHeader, sampleRow(1), sampleRow(2), []interface{}{""}, sampleRow(3))
+	setRows("Сводка", []interface{}{"Итого", "Сумма"}, []interface{}{"Откликов", 5})
+	setRows("Осень", sampleHeader, sampleRow(4), sampleRow(5))
+
+	path := filepath.Join(t.TempDir(), "sheets.xlsx")
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	tf, err := openTable(path, models.FormatXLSX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = tf.Close() })
+	return tf
+}
+
+func TestResolveSheets(t *testing.T) {
+	tf := openSheetsWorkbook(t)
+	profile := models.DefaultImportProfile()
+
+	cases := []struct {
+		name string
+		opts models.ImportOptions
+		want []string
+	}{
+		{"по умолчанию первый", models.ImportOptions{}, []string{"Весна"}},
+		{"все подходящие", models.ImportOptions{AllSheets: true}, []string{"Весна", "Осень"}},
+		{"выбранные без повторов", models.ImportOptions{Sheets: []string{"Осень", " Весна ", "Осень"}}, []string{"Осень", "Весна"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := resolveSheets(tf, profile, c.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("sheets = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestResolveSheetsErrors(t *testing.T) {
+	tf := openSheetsWorkbook(t)
+	profile := models.DefaultImportProfile()
+
+	_, err := resolveSheets(tf, profile, models.ImportOptions{Sheets: []string{"Весна", "Зима"}})
+	var notFound *custom_errors.SheetNotFoundError
+	if !errors.As(err, &notFound) || notFound.Sheet != "Зима" {
+		t.Errorf("unknown sheet: err = %v, want SheetNotFoundError for Зима", err)
+	}
+
+	_, err = resolveSheets(tf, profile, models.ImportOptions{Sheets: []string{"Сводка"}})
+	var missing *custom_errors.MissingColumnsError
+	if !errors.As(err, &missing) || missing.Sheet != "Сводка" {
+		t.Errorf("mismatched sheet: err = %v, want MissingColumnsError for Сводка", err)
+	}
+
+	_, err = resolveSheets(tf, profile, models.ImportOptions{Sheets: []string{"Пусто"}})
+	if !errors.Is(err, custom_errors.ErrNoXLSXData) {
+		t.Errorf("empty sheet: err = %v, want ErrNoXLSXData", err)
+	}
+
+	partner := profile
+	partner.Columns = map[string]models.ColumnMapping{
+		models.FieldLastName: {Aliases: []string{"ФИО"}, Required: true},
+	}
+	if _, err = resolveSheets(tf, partner, models.ImportOptions{AllSheets: true}); !errors.Is(err, custom_errors.ErrNoMatchingSheets) {
+		t.Errorf("no matching sheets: err = %v, want ErrNoMatchingSheets", err)
+	}
+}
+
+func TestSheetInfos(t *testing.T) {
+	tf := openSheetsWorkbook(t)
+
+	got, err := sheetInfos(tf, models.DefaultImportProfile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name    string
+		rows    int
+		matches bool
+		missing bool
+	}{
+		{"Весна", 3, true, false},
+		{"Сводка", 1, false, true},
+		{"Пусто", 0, false, false},
+		{"Осень", 2, true, false},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("sheets = %d, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Index != i || g.Name != w.name || g.Rows != w.rows || g.Matches != w.matches || (len(g.MissingColumns) > 0) != w.missing {
+			t.Errorf("sheet %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestSheetStats(t *testing.T) {
+	scanned := scanResult{
+		total:   5,
+		bySheet: map[string]int{"Весна": 3, "Осень": 2},
+		errors: []models.ImportRowError{
+			{Sheet: "Весна", Row: 5},
+			{Sheet: "Осень", Row: 2},
+			{Sheet: "Осень", Row: 3},
+		},
+	}
+	stats := models.InsertStats{BySheet: map[string]models.SheetInsertStats{
+		"Весна": {Inserted: 2, Skipped: 1},
+		"Осень": {Inserted: 1, Failed: 1},
+	}}
+
+	got := sheetStats([]string{"Осень", "Весна"}, scanned, stats)
+	want := []models.SheetStats{
+		{Sheet: "Осень", TotalRows: 2, InsertedRows: 1, FailedRows: 1, ErrorsCount: 2},
+		{Sheet: "Весна", TotalRows: 3, InsertedRows: 2, SkippedRows: 1, ErrorsCount: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sheetStats = %+v, want %+v", got, want)
+	}
+	if got := sheetStats(nil, scanned, stats); got != nil {
+		t.Errorf("csv sheetStats = %+v, want nil", got)
+	}
+}
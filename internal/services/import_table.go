@@ -0,0 +1,270 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kurushqosimi/x5-intern-hiring/internal/custom_errors"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// rowSource - построчное чтение листа xlsx или csv; первая строка — заголовок
+type rowSource interface {
+	Next() bool
+	Columns() ([]string, error)
+	Error() error
+	Close() error
+}
+
+// xlsxRows - итератор excelize: лист не материализуется целиком, как в GetRows.
+// Сырые значения: ячейки с форматом даты приходят серийным номером,
+// а не строкой в локали того, кто сохранял файл.
+type xlsxRows struct {
+	*excelize.Rows
+}
+
+func (r xlsxRows) Columns() ([]string, error) {
+	return r.Rows.Columns(excelize.Options{RawCellValue: true})
+}
+
+// tableFile - загруженный файл на диске, открытый для чтения
+type tableFile struct {
+	path     string
+	format   string
+	xl       *excelize.File // только для xlsx
+	date1904 bool           // книга Excel с системой дат 1904 (старые файлы с Mac)
+	// заголовки разобранных листов (для csv ключ ""), нужны отчёту об ошибках
+	headers map[string][]string
+}
+
+func openTable(path, format string) (*tableFile, error) {
+	t := &tableFile{path: path, format: format, headers: map[string][]string{}}
+	if format == models.FormatCSV || format == models.FormatTSV {
+		return t, nil
+	}
+
+	xl, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", custom_errors.ErrInvalidXLSX, err)
+	}
+	props, err := xl.GetWorkbookProps()
+	if err != nil {
+		_ = xl.Close()
+		return nil, fmt.Errorf("%w: %v", custom_errors.ErrInvalidXLSX, err)
+	}
+	t.xl = xl
+	t.date1904 = props.Date1904 != nil && *props.Date1904
+	return t, nil
+}
+
+func (t *tableFile) Close() error {
+	if t.xl == nil {
+		return nil
+	}
+	return t.xl.Close()
+}
+
+func (t *tableFile) isCSV() bool {
+	return t.xl == nil
+}
+
+// sheetNames - листы книги; у csv листов нет
+func (t *tableFile) sheetNames() []string {
+	if t.isCSV() {
+		return nil
+	}
+	return t.xl.GetSheetList()
+}
+
+func (t *tableFile) rows(sheet string) (rowSource, error) {
+	if t.isCSV() {
+		return openCSVRows(t.path, t.format)
+	}
+	rows, err := t.xl.Rows(sheet)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", custom_errors.ErrNoXLSXData, err)
+	}
+	return xlsxRows{rows}, nil
+}
+
+// header - первая строка листа (для csv sheet не используется)
+func (t *tableFile) header(sheet string) ([]string, error) {
+	src, err := t.rows(sheet)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = src.Close() }()
+
+	if !src.Next() {
+		if err = src.Error(); err != nil {
+			return nil, err
+		}
+		return nil, custom_errors.ErrNoXLSXData
+	}
+	header, err := src.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", custom_errors.ErrInvalidXLSX, err)
+	}
+	return header, nil
+}
+
+// estimateRows - сколько строк данных примерно будет (для прогресса до окончания разбора):
+// для xlsx по размерности листа, для csv по числу переводов строки
+func (t *tableFile) estimateRows(sheets []string) int {
+	if t.isCSV() {
+		n, err := countLines(t.path)
+		if err != nil || n == 0 {
+			return 0
+		}
+		return n - 1
+	}
+
+	total := 0
+	for _, sheet := range sheets {
+		dim, err := t.xl.GetSheetDimension(sheet)
+		if err != nil || dim == "" {
+			continue
+		}
+		last := dim
+		if i := strings.IndexByte(dim, ':'); i >= 0 {
+			last = dim[i+1:]
+		}
+		if _, row, err := excelize.CellNameToCoordinates(last); err == nil && row > 1 {
+			total += row - 1
+		}
+	}
+	return total
+}
+
+// scan - потоковый разбор выбранных листов (для csv — всего файла): каждая разобранная
+// строка уходит в emit, ошибки разбора копятся. Строки и ошибки xlsx помечаются листом,
+// номера строк — в пределах листа.
+func (t *tableFile) scan(profile models.ImportProfile, sheets []string, emit func(models.ParsedRow) error) ([]models.ImportRowError, error) {
+	dates := newDateParser(profile, t.date1904)
+
+	if t.isCSV() {
+		return t.scanSheet("", profile, dates, emit)
+	}
+
+	if len(sheets) == 0 {
+		sheet := t.xl.GetSheetName(0)
+		if sheet == "" {
+			return nil, custom_errors.ErrNoXLSXSheets
+		}
+		sheets = []string{sheet}
+	}
+
+	var parseErrors []models.ImportRowError
+	for _, sheet := range sheets {
+		sheetErrors, err := t.scanSheet(sheet, profile, dates, emit)
+		if err != nil {
+			// из нескольких листов какой-то может оказаться пустым
+			if errors.Is(err, custom_errors.ErrNoXLSXData) && len(sheets) > 1 {
+				continue
+			}
+			var missing *custom_errors.MissingColumnsError
+			if errors.As(err, &missing) && len(sheets) > 1 {
+				missing.Sheet = sheet
+			}
+			return nil, err
+		}
+		parseErrors = append(parseErrors, sheetErrors...)
+	}
+	return parseErrors, nil
+}
+
+func (t *tableFile) scanSheet(sheet string, profile models.ImportProfile, dates dateParser, emit func(models.ParsedRow) error) ([]models.ImportRowError, error) {
+	src, err := t.rows(sheet)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = src.Close() }()
+
+	if !src.Next() {
+		if err = src.Error(); err != nil {
+			return nil, err
+		}
+		return nil, custom_errors.ErrNoXLSXData
+	}
+	header, err := src.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", custom_errors.ErrInvalidXLSX, err)
+	}
+	col, err := matchColumns(profile, header)
+	if err != nil {
+		return nil, err
+	}
+	t.headers[sheet] = header
+
+	var (
+		parseErrors []models.ImportRowError
+		rowNum      = 1
+		dataRows    = 0
+	)
+	for src.Next() {
+		rowNum++
+		r, err := src.Columns()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", custom_errors.ErrInvalidXLSX, err)
+		}
+		// пустая строка
+		if len(r) == 0 || allEmpty(r) {
+			continue
+		}
+		dataRows++
+
+		row, rowErrors, ok := parseRow(col, dates, header, r, rowNum)
+		for i := range rowErrors {
+			rowErrors[i].Sheet = sheet
+			// отклонённая строка целиком попадёт в отчёт об ошибках
+			if !ok {
+				rowErrors[i].Rejected = true
+				rowErrors[i].Cells = r
+			}
+		}
+		parseErrors = append(parseErrors, rowErrors...)
+		if !ok {
+			continue
+		}
+		row.Sheet = sheet
+		row.Row = rowNum
+		row.Cells = r
+		if err = emit(row); err != nil {
+			return nil, err
+		}
+	}
+	if err = src.Error(); err != nil {
+		return nil, err
+	}
+	if dataRows == 0 {
+		return nil, custom_errors.ErrNoXLSXData
+	}
+
+	return parseErrors, nil
+}
+
+// ScanFile - потоковый разбор файла на диске без записи в БД (первый лист для xlsx).
+// Возвращает число разобранных строк и ошибки разбора.
+func ScanFile(path string, profile models.ImportProfile, emit func(models.ParsedRow)) (int, []models.ImportRowError, error) {
+	format := detectFormat(path, nil)
+	if format == "" {
+		return 0, nil, custom_errors.ErrUnsupportedFormat
+	}
+	t, err := openTable(path, format)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer func() { _ = t.Close() }()
+
+	total := 0
+	rowErrors, err := t.scan(profile, nil, func(r models.ParsedRow) error {
+		total++
+		if emit != nil {
+			emit(r)
+		}
+		return nil
+	})
+	return total, rowErrors, err
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+const benchRows = 10_000
+
+// BenchmarkScanFile - потоковый разбор xlsx против прежнего GetRows на одной и той же книге.
+//
+//	go test ./internal/services -run '^$' -bench ScanFile -benchmem
+func BenchmarkScanFile(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "bench.xlsx")
+	if err := WriteSampleXLSX(path, benchRows); err != nil {
+		b.Fatal(err)
+	}
+	profile := models.DefaultImportProfile()
+
+	b.Run("stream", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			total, rowErrors, err := ScanFile(path, profile, nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if total != benchRows || len(rowErrors) != 0 {
+				b.Fatalf("rows = %d, errors = %d", total, len(rowErrors))
+			}
+		}
+	})
+
+	// прежний путь: весь лист в памяти, без разбора строк
+	b.Run("GetRows", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			xl, err := excelize.OpenFile(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			rows, err := xl.GetRows(xl.GetSheetName(0))
+			_ = xl.Close()
+			if err != nil {
+				b.Fatal(err)
+			}
+			if len(rows) != benchRows+1 {
+				b.Fatalf("rows = %d", len(rows))
+			}
+		}
+	})
+}
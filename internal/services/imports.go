@@ -1,7 +1,6 @@
 package services
 
 import (
-	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
@@ -10,193 +9,634 @@ import (
 	"github.com/google/uuid"
 	"github.com/kurushqosimi/x5-intern-hiring/internal/custom_errors"
 	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
-	"github.com/xuri/excelize/v2"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/repositories"
+	"github.com/kurushqosimi/x5-intern-hiring/pkg/emailaddr"
+	"github.com/kurushqosimi/x5-intern-hiring/pkg/phone"
 	"io"
 	"mime/multipart"
+	"os"
+	"path/filepath"
 	"strings"
-	"time"
 )
 
-func (s *Service) ProcessXLSX(ctx context.Context, fileHeader *multipart.FileHeader) (*models.XLSXProcRes, error) {
-	metadata, content, err := s.readFile(fileHeader)
+type importJob struct {
+	metadata *models.FileMetaData
+	profile  models.ImportProfile
+	sheets   []string // выбранные листы xlsx
+	path     string   // временный файл, удаляется после импорта
+}
+
+// EnqueueXLSX - сохраняет метаданные и ставит файл в очередь фонового импорта
+func (s *Service) EnqueueXLSX(ctx context.Context, fileHeader *multipart.FileHeader, opts models.ImportOptions) (_ *models.FileMetaData, err error) {
+	metadata, path, err := s.readFile(fileHeader, opts.Format)
 	if err != nil {
 		return nil, err
 	}
+	// после постановки в очередь файл удалит воркер
+	defer func() {
+		if err != nil {
+			_ = os.Remove(path)
+		}
+	}()
 
-	if err = s.repo.InsertImport(ctx, metadata); err != nil {
+	if err = s.checkDuplicate(ctx, metadata, opts); err != nil {
 		return nil, err
 	}
-
-	parsedRows, parsedErrors, err := s.parseXLSX(content)
+	profile, err := s.resolveImportProfile(ctx, opts.Profile)
 	if err != nil {
-		_ = s.repo.SetImportFailed(ctx, metadata.ImportID, models.FileFailed)
 		return nil, err
 	}
-	inserted, skipped, err := s.repo.InsertXLSXData(ctx, metadata.ImportID, parsedRows)
+	// битый файл или не тот заголовок — сразу отвечаем загрузившему, а не через статус импорта
+	sheets, err := s.checkHeader(path, metadata.Format, profile, opts)
 	if err != nil {
-		_ = s.repo.SetImportStats(ctx, metadata.ImportID, models.FileFailed, len(parsedRows), inserted, skipped)
+		return nil, err
+	}
+	metadata.Status = models.FileQueued
+	metadata.ProfileCode = profile.Code
+	metadata.UploadedBy = opts.UploadedBy
+	metadata.Forced = opts.Force
+
+	if err = s.insertImport(ctx, metadata); err != nil {
 		return nil, err
 	}
 
-	_ = s.repo.SetImportStats(ctx, metadata.ImportID, models.FileParsed, len(parsedRows), inserted, skipped)
+	select {
+	case s.importJobs <- importJob{metadata: metadata, profile: profile, sheets: sheets, path: path}:
+		return metadata, nil
+	default:
+		_ = s.repo.SetImportFailed(ctx, metadata.ImportID, "очередь импорта переполнена")
+		return nil, custom_errors.ErrImportQueueFull
+	}
+}
 
-	return &models.XLSXProcRes{
-		ImportId:     metadata.ImportID.String(),
-		FileSha256:   metadata.FileSha256,
-		TotalRows:    len(parsedRows),
-		InsertedRows: inserted,
-		SkippedRows:  skipped,
-		Errors:       parsedErrors,
-	}, nil
+// RunImportWorker - обрабатывает очередь импортов до отмены ctx
+func (s *Service) RunImportWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-s.importJobs:
+			_, _ = s.runImport(ctx, job.metadata, job.profile, job.sheets, job.path)
+			_ = os.Remove(job.path)
+		}
+	}
 }
 
-func (s *Service) readFile(fileHeader *multipart.FileHeader) (*models.FileMetaData, []byte, error) {
-	f, err := fileHeader.Open()
+// FailInterruptedImports - вызывается при старте: очередь в памяти, незавершённые импорты уже не доделать.
+// Трогает только импорты своего владельца: import-watch в это время может вести свой
+func (s *Service) FailInterruptedImports(ctx context.Context) (int, error) {
+	return s.repo.FailInterruptedImports(ctx, s.owner)
+}
+
+// ProcessXLSX - синхронный импорт файла
+func (s *Service) ProcessXLSX(ctx context.Context, fileHeader *multipart.FileHeader, opts models.ImportOptions) (*models.XLSXProcRes, error) {
+	metadata, path, err := s.readFile(fileHeader, opts.Format)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%v: %w", err, custom_errors.ErrFailedToOpenFile)
+		return nil, err
 	}
-	defer f.Close()
+	defer func() { _ = os.Remove(path) }()
 
-	// чтобы и sha256 посчитать и excelize прочитать — читаем в память (для больших файлов лучше в temp file)
-	buf, err := io.ReadAll(io.LimitReader(f, 50<<20)) // 50MB лимит
+	return s.processFile(ctx, metadata, path, opts)
+}
+
+// ProcessFile - то же, что ProcessXLSX, для файла на диске (файл не удаляется)
+func (s *Service) ProcessFile(ctx context.Context, path string, opts models.ImportOptions) (*models.XLSXProcRes, error) {
+	metadata, err := fileMetadata(path, filepath.Base(path), opts.Format)
+	if err != nil {
+		return nil, err
+	}
+	return s.processFile(ctx, metadata, path, opts)
+}
+
+func (s *Service) processFile(ctx context.Context, metadata *models.FileMetaData, path string, opts models.ImportOptions) (*models.XLSXProcRes, error) {
+	if err := s.checkDuplicate(ctx, metadata, opts); err != nil {
+		return nil, err
+	}
+	profile, err := s.resolveImportProfile(ctx, opts.Profile)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%v: %w", err, custom_errors.ErrFailedToReadFile)
+		return nil, err
+	}
+	sheets, err := s.checkHeader(path, metadata.Format, profile, opts)
+	if err != nil {
+		return nil, err
 	}
+	metadata.ProfileCode = profile.Code
+	metadata.UploadedBy = opts.UploadedBy
+	metadata.Forced = opts.Force
 
-	sum := sha256.Sum256(buf)
-	fileSHA := hex.EncodeToString(sum[:])
+	if err = s.insertImport(ctx, metadata); err != nil {
+		return nil, err
+	}
 
-	return &models.FileMetaData{
-		ImportID:     uuid.New(),
-		UploadedBy:   uuid.Nil,
-		FileName:     fileHeader.Filename,
-		FileSha256:   fileSHA,
-		Status:       models.FileCreated,
-		TotalRows:    0,
-		InsertedRows: 0,
-		SkippedRows:  0,
-	}, buf, nil
+	return s.runImport(ctx, metadata, profile, sheets, path)
 }
 
-func (s *Service) parseXLSX(buf []byte) ([]models.ParsedRow, []string, error) {
-	xl, err := excelize.OpenReader(bytes.NewReader(buf))
+// DryRunXLSX - разбор файла и вставка в откатываемой транзакции: импорт не создаётся
+func (s *Service) DryRunXLSX(ctx context.Context, fileHeader *multipart.FileHeader, opts models.ImportOptions) (*models.DryRunResult, error) {
+	metadata, path, err := s.readFile(fileHeader, opts.Format)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w: %v", custom_errors.ErrInvalidXLSX, err)
+		return nil, err
 	}
-	defer func() { _ = xl.Close() }()
+	defer func() { _ = os.Remove(path) }()
 
-	sheet := xl.GetSheetName(0)
-	if sheet == "" {
-		return nil, nil, custom_errors.ErrNoXLSXSheets
+	return s.dryRun(ctx, metadata, path, opts)
+}
+
+// DryRunFile - то же, что DryRunXLSX, для файла на диске (файл не удаляется)
+func (s *Service) DryRunFile(ctx context.Context, path string, opts models.ImportOptions) (*models.DryRunResult, error) {
+	metadata, err := fileMetadata(path, filepath.Base(path), opts.Format)
+	if err != nil {
+		return nil, err
 	}
+	return s.dryRun(ctx, metadata, path, opts)
+}
 
-	rows, err := xl.GetRows(sheet)
+func (s *Service) dryRun(ctx context.Context, metadata *models.FileMetaData, path string, opts models.ImportOptions) (*models.DryRunResult, error) {
+	profile, err := s.resolveImportProfile(ctx, opts.Profile)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w: %v", custom_errors.ErrNoXLSXData, err)
+		return nil, err
 	}
-	if len(rows) < 2 {
-		return nil, nil, custom_errors.ErrNoXLSXData
+	sheets, err := s.checkHeader(path, metadata.Format, profile, opts)
+	if err != nil {
+		return nil, err
 	}
-	header := rows[0]
-	col := indexColumns(header)
 
-	var parsed []models.ParsedRow
-	var parseErrors []string
+	t, err := openTable(path, metadata.Format)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = t.Close() }()
 
-	for i := 1; i < len(rows); i++ {
-		r := rows[i]
-		// пустая строка
-		if len(r) == 0 || allEmpty(r) {
-			continue
+	w, err := s.repo.NewImportWriter(ctx, uuid.New())
+	if err != nil {
+		return nil, err
+	}
+	defer w.Rollback(ctx)
+
+	sample := make([]models.ParsedRow, 0, dryRunSampleSize)
+	scanned, err := s.streamRows(ctx, w, t, profile, sheets, nil, func(r models.ParsedRow) {
+		if len(sample) < dryRunSampleSize {
+			sample = append(sample, r)
 		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	stats := w.Stats()
+
+	res := &models.DryRunResult{
+		FileName:          metadata.FileName,
+		FileSha256:        metadata.FileSha256,
+		TotalRows:         scanned.total,
+		WouldInsert:       stats.Inserted,
+		Duplicates:        stats.Skipped,
+		Failed:            stats.Failed,
+		CandidatesCreated: stats.CandidatesCreated,
+		CandidatesMatched: stats.CandidatesMatched,
+		CandidatesUpdated: stats.CandidatesUpdated,
+		Rejected:          len(scanned.errors),
+		Errors:            scanned.errors,
+		Sheets:            sheetStats(sheets, scanned, stats),
+		Sample:            sample,
+	}
+	if res.Errors == nil {
+		res.Errors = []models.ImportRowError{}
+	}
+
+	prev, found, err := s.repo.FindImportBySha256(ctx, metadata.FileSha256)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		res.AlreadyImported = &prev
+	}
+	return res, nil
+}
 
-		get := func(name string) string {
-			idx, ok := col[name]
-			if !ok || idx >= len(r) {
-				return ""
-			}
-			return strings.TrimSpace(r[idx])
+// checkDuplicate - повторная загрузка того же файла создала бы дубли кандидатов
+func (s *Service) checkDuplicate(ctx context.Context, metadata *models.FileMetaData, opts models.ImportOptions) error {
+	if opts.Force {
+		return nil
+	}
+	prev, found, err := s.repo.FindImportBySha256(ctx, metadata.FileSha256)
+	if err != nil {
+		return err
+	}
+	if found {
+		return &custom_errors.DuplicateImportError{ImportID: prev.ImportID, Status: prev.Status, CreatedAt: prev.CreatedAt}
+	}
+	return nil
+}
+
+// insertImport - checkDuplicate не защищает от двух одновременных загрузок одного файла:
+// вторую отсекает уникальный индекс, и она получает тот же DuplicateImportError
+func (s *Service) insertImport(ctx context.Context, metadata *models.FileMetaData) error {
+	metadata.Owner = s.owner
+	for attempt := 1; ; attempt++ {
+		err := s.repo.InsertImport(ctx, metadata)
+		if !errors.Is(err, repositories.ErrImportFileDuplicate) {
+			return err
 		}
+		prev, found, err := s.repo.FindImportBySha256(ctx, metadata.FileSha256)
+		if err != nil {
+			return err
+		}
+		if found {
+			return &custom_errors.DuplicateImportError{ImportID: prev.ImportID, Status: prev.Status, CreatedAt: prev.CreatedAt}
+		}
+		// тот импорт успел упасть или откатиться — индекс больше не мешает, пробуем ещё раз;
+		// если файл снова и снова перехватывают, отвечаем дублем без ссылки на импорт
+		if attempt == insertImportAttempts {
+			return &custom_errors.DuplicateImportError{Status: models.FileProcessing}
+		}
+	}
+}
 
-		lastName := get(models.LastName)
-		firstName := get(models.FirstName)
-		email := strings.ToLower(strings.TrimSpace(get(models.Email)))
-		phone := normalizePhone(get(models.Cellphone))
-		telegram := strings.TrimSpace(get(models.Telegram))
+func (s *Service) runImport(ctx context.Context, metadata *models.FileMetaData, profile models.ImportProfile, sheets []string, path string) (*models.XLSXProcRes, error) {
+	importID := metadata.ImportID
+	_ = s.repo.SetImportProcessing(ctx, importID)
 
-		if lastName == "" && firstName == "" {
-			parseErrors = append(parseErrors, fmt.Sprintf("строка %d: пустое имя", i+1))
-			continue
+	fail := func(err error) (*models.XLSXProcRes, error) {
+		_ = s.repo.SetImportFailed(ctx, importID, err.Error())
+		return nil, err
+	}
+
+	t, err := openTable(path, metadata.Format)
+	if err != nil {
+		return fail(err)
+	}
+	defer func() { _ = t.Close() }()
+
+	// точное число строк известно только после разбора, для прогресса достаточно оценки
+	_ = s.repo.SetImportTotal(ctx, importID, t.estimateRows(sheets))
+
+	w, err := s.repo.NewImportWriter(ctx, importID)
+	if err != nil {
+		return fail(err)
+	}
+
+	scanned, err := s.streamRows(ctx, w, t, profile, sheets, func(processed int) {
+		_ = s.repo.SetImportProgress(ctx, importID, processed, w.Stats())
+	}, nil)
+	if err == nil {
+		err = w.WriteErrors(ctx, scanned.errors)
+	}
+	if err == nil {
+		err = w.Commit(ctx)
+	}
+	if err != nil {
+		// ошибка не по конкретной строке (соединение, отмена) — ничего из файла не сохранено
+		w.Rollback(ctx)
+		return fail(err)
+	}
+	stats := w.Stats()
+
+	bySheet := sheetStats(sheets, scanned, stats)
+	if len(bySheet) > 0 {
+		_ = s.repo.SetImportSheets(ctx, importID, bySheet)
+	}
+	_ = s.repo.SetImportHeaders(ctx, importID, t.headers, t.date1904)
+	status := models.FileParsed
+	if stats.Failed > 0 {
+		status = models.FilePartial
+	}
+	_ = s.repo.SetImportStats(ctx, importID, status, scanned.total, stats)
+
+	errs := scanned.errors
+	if errs == nil {
+		errs = []models.ImportRowError{}
+	}
+
+	return &models.XLSXProcRes{
+		ImportId:          importID.String(),
+		FileSha256:        metadata.FileSha256,
+		TotalRows:         scanned.total,
+		InsertedRows:      stats.Inserted,
+		SkippedRows:       stats.Skipped,
+		FailedRows:        stats.Failed,
+		CandidatesCreated: stats.CandidatesCreated,
+		CandidatesMatched: stats.CandidatesMatched,
+		CandidatesUpdated: stats.CandidatesUpdated,
+		Errors:            errs,
+		Sheets:            bySheet,
+	}, nil
+}
+
+// scanResult - итог потокового разбора
+type scanResult struct {
+	total   int            // строк, прошедших разбор
+	bySheet map[string]int // то же по листам xlsx
+	errors  []models.ImportRowError
+}
+
+// streamRows - разбор файла и вставка пачками по importBatchSize строк.
+// onBatch получает число отправленных в БД строк, onRow — каждую разобранную строку.
+func (s *Service) streamRows(
+	ctx context.Context,
+	w *repositories.ImportWriter,
+	t *tableFile,
+	profile models.ImportProfile,
+	sheets []string,
+	onBatch func(processed int),
+	onRow func(models.ParsedRow),
+) (scanResult, error) {
+	res := scanResult{bySheet: map[string]int{}}
+	batch := make([]models.ParsedRow, 0, importBatchSize)
+	processed := 0
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
 		}
-		if email == "" && phone == "" {
-			parseErrors = append(parseErrors, fmt.Sprintf("строка %d: имейл и номер телефона пусты", i+1))
-			continue
+		if err := w.Write(ctx, batch); err != nil {
+			return err
+		}
+		processed += len(batch)
+		batch = batch[:0]
+		if onBatch != nil {
+			onBatch(processed)
+		}
+		return nil
+	}
+
+	rowErrors, err := t.scan(profile, sheets, func(r models.ParsedRow) error {
+		res.total++
+		if r.Sheet != "" {
+			res.bySheet[r.Sheet]++
+		}
+		if onRow != nil {
+			onRow(r)
+		}
+		batch = append(batch, r)
+		if len(batch) >= importBatchSize {
+			return flush()
 		}
+		return nil
+	})
+	if err != nil {
+		return res, err
+	}
+	if err = flush(); err != nil {
+		return res, err
+	}
+	// строки, отвергнутые БД, — такие же ошибки импорта, как ошибки разбора
+	res.errors = append(rowErrors, w.Failures()...)
+	return res, nil
+}
+
+func (s *Service) GetImportProgress(ctx context.Context, importID uuid.UUID) (models.ImportProgress, error) {
+	return s.repo.GetImportProgress(ctx, importID)
+}
+
+func (s *Service) GetImport(ctx context.Context, importID uuid.UUID) (models.ImportDetails, error) {
+	progress, err := s.repo.GetImportProgress(ctx, importID)
+	if err != nil {
+		return models.ImportDetails{}, err
+	}
+	rowErrors, err := s.repo.ListImportErrors(ctx, importID, importErrorsLimit)
+	if err != nil {
+		return models.ImportDetails{}, err
+	}
+	apps, err := s.repo.GetImportApplicationsSummary(ctx, importID)
+	if err != nil {
+		return models.ImportDetails{}, err
+	}
+	return models.ImportDetails{ImportProgress: progress, Errors: rowErrors, Applications: apps}, nil
+}
+
+func (s *Service) ListImports(ctx context.Context, p models.ListImportsParams) (models.ListImportsResponse, error) {
+	p.Limit, p.Offset = pageBounds(p.Limit, p.Offset)
+	items, total, err := s.repo.ListImports(ctx, p)
+	if err != nil {
+		return models.ListImportsResponse{}, err
+	}
+	return models.ListImportsResponse{
+		Items:  items,
+		Total:  total,
+		Limit:  p.Limit,
+		Offset: p.Offset,
+	}, nil
+}
+
+func IsImportNotFound(err error) bool {
+	return errors.Is(err, repositories.ErrImportNotFound)
+}
+
+// RevertImport - откат импорта: удаляются нетронутые заявки и оставшиеся без заявок кандидаты
+func (s *Service) RevertImport(ctx context.Context, importID, revertedBy uuid.UUID) (models.RevertImportResponse, error) {
+	return s.repo.RevertImport(ctx, importID, revertedBy)
+}
+
+func IsImportNotRevertible(err error) bool {
+	return errors.Is(err, repositories.ErrImportNotRevertible)
+}
+
+func IsIntakeImportNotRevertible(err error) bool {
+	return errors.Is(err, repositories.ErrIntakeImportNotRevertible)
+}
+
+func IsRevertCandidatesLocked(err error) bool {
+	return errors.Is(err, repositories.ErrRevertCandidatesLocked)
+}
+
+// readFile - загруженный файл сохраняется во временный файл (не держим его в памяти);
+// удалить его должен вызывающий
+func (s *Service) readFile(fileHeader *multipart.FileHeader, format string) (*models.FileMetaData, string, error) {
+	src, err := fileHeader.Open()
+	if err != nil {
+		return nil, "", fmt.Errorf("%v: %w", err, custom_errors.ErrFailedToOpenFile)
+	}
+	defer src.Close()
+
+	dst, err := os.CreateTemp("", "import-*"+strings.ToLower(filepath.Ext(fileHeader.Filename)))
+	if err != nil {
+		return nil, "", fmt.Errorf("%v: %w", err, custom_errors.ErrFailedToReadFile)
+	}
+	path := dst.Name()
+
+	n, err := io.Copy(dst, io.LimitReader(src, maxUploadSize+1))
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, "", fmt.Errorf("%v: %w", err, custom_errors.ErrFailedToReadFile)
+	}
+	if n > maxUploadSize {
+		_ = os.Remove(path)
+		return nil, "", custom_errors.ErrFileTooLarge
+	}
+
+	metadata, err := fileMetadata(path, fileHeader.Filename, format)
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, "", err
+	}
+	return metadata, path, nil
+}
+
+// fileMetadata - sha256 и формат файла на диске
+func fileMetadata(path, fileName, format string) (*models.FileMetaData, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, custom_errors.ErrFailedToOpenFile)
+	}
+	defer f.Close()
+
+	head := make([]byte, 8)
+	n, _ := io.ReadFull(f, head)
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("%v: %w", err, custom_errors.ErrFailedToReadFile)
+	}
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return nil, fmt.Errorf("%v: %w", err, custom_errors.ErrFailedToReadFile)
+	}
+
+	if format == "" {
+		format = detectFormat(fileName, head[:n])
+	}
+	if format == "" {
+		return nil, custom_errors.ErrUnsupportedFormat
+	}
 
-		appliedAt, err := parseAppliedAt(get(models.ApplicationDate))
+	return &models.FileMetaData{
+		ImportID:     uuid.New(),
+		FileName:     fileName,
+		FileSha256:   hex.EncodeToString(h.Sum(nil)),
+		Format:       format,
+		Status:       models.FileCreated,
+		TotalRows:    0,
+		InsertedRows: 0,
+		SkippedRows:  0,
+	}, nil
+}
+
+// checkHeader - быстрая проверка перед постановкой в очередь: файл открывается
+// и в первой строке есть все обязательные столбцы профиля. Для xlsx возвращает листы для импорта.
+func (s *Service) checkHeader(path, format string, profile models.ImportProfile, opts models.ImportOptions) ([]string, error) {
+	t, err := openTable(path, format)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = t.Close() }()
+
+	if t.isCSV() {
+		if len(opts.Sheets) > 0 || opts.AllSheets {
+			return nil, custom_errors.ErrSheetsNotApplicable
+		}
+		header, err := t.header("")
 		if err != nil {
-			parseErrors = append(parseErrors, fmt.Sprintf("строка %d: инвалидная дата подачи: %v", i+1, err))
-			continue
+			return nil, err
+		}
+		_, err = matchColumns(profile, header)
+		return nil, err
+	}
+	return resolveSheets(t, profile, opts)
+}
+
+// parseRow - одна строка файла в ParsedRow (rowNum — номер строки в файле для ошибок).
+// ok=false — строку нужно отбросить; ошибки могут быть и при ok=true (невалидное необязательное поле).
+func parseRow(col columnIndex, dates dateParser, header []string, r []string, rowNum int) (row models.ParsedRow, rowErrors []models.ImportRowError, ok bool) {
+	get := func(field string) string {
+		idx, ok := col[field]
+		if !ok || idx >= len(r) {
+			return ""
+		}
+		return strings.TrimSpace(r[idx])
+	}
+	rowErr := func(field, code, message string) models.ImportRowError {
+		e := models.ImportRowError{Row: rowNum, Code: code, Message: message}
+		if idx, ok := col[field]; ok && idx < len(header) {
+			e.Column = header[idx]
 		}
+		return e
+	}
+
+	lastName := get(models.FieldLastName)
+	firstName := get(models.FieldFirstName)
+	email := get(models.FieldEmail)
+	phoneRaw := get(models.FieldPhone)
+	telegram := strings.TrimSpace(get(models.FieldTelegram))
+
+	if lastName == "" && firstName == "" {
+		return row, []models.ImportRowError{rowErr(models.FieldLastName, models.RowErrEmptyName, "пустое имя")}, false
+	}
+	if email == "" && phoneRaw == "" {
+		return row, []models.ImportRowError{rowErr(models.FieldEmail, models.RowErrNoContacts, "имейл и номер телефона пусты")}, false
+	}
 
-		var by *int
-		if s := get(models.YearBorn); s != "" {
-			v, e := parseYear(s)
-			if e != nil {
-				parseErrors = append(parseErrors, fmt.Sprintf("строка %d: инвалидная дата рождения: %v", i+1, e))
-			} else {
-				by = &v
-			}
+	// невалидный email сохраняется с пометкой: письма на него не ставятся в очередь
+	var emailNorm, emailInvalid string
+	if email != "" {
+		if err := emailaddr.Validate(email); err != nil {
+			emailInvalid = err.Error()
+			rowErrors = append(rowErrors, rowErr(models.FieldEmail, models.RowErrInvalidEmail, fmt.Sprintf("невалидный email %q: %v", email, err)))
 		}
+		emailNorm = emailaddr.Normalize(email)
+	}
 
-		raw := map[string]any{}
-		for j, name := range header {
-			if name == "" || j >= len(r) {
-				continue
-			}
-			raw[name] = r[j]
+	// невалидный телефон при наличии email — только предупреждение, контакт не сохраняем
+	phoneNorm, err := phone.Normalize(phoneRaw)
+	if err != nil && phoneRaw != "" {
+		if email == "" {
+			return row, []models.ImportRowError{rowErr(models.FieldPhone, models.RowErrInvalidPhone, fmt.Sprintf("невалидный номер телефона: %v", err))}, false
 		}
+		rowErrors = append(rowErrors, rowErr(models.FieldPhone, models.RowErrInvalidPhone, fmt.Sprintf("невалидный номер телефона: %v", err)))
+		phoneRaw = ""
+	}
 
-		parsed = append(parsed, models.ParsedRow{
-			LastName:        lastName,
-			FirstName:       firstName,
-			Email:           email,
-			Phone:           phone,
-			Telegram:        telegram,
-			ResumeURL:       get(models.ResumeURL),
-			Priority1:       get(models.FirstPriority),
-			Priority2:       get(models.SecondPriority),
-			Course:          get(models.Course),
-			Specialty:       get(models.Speciality),
-			SpecialtyOther:  get(models.OtherSpeciality),
-			Schedule:        get(models.Schedule),
-			City:            get(models.City),
-			CityOther:       get(models.OtherCity),
-			Source:          get(models.Source),
-			BirthYear:       by,
-			Citizenship:     get(models.Citizenship),
-			University:      get(models.University),
-			UniversityOther: get(models.OtherUniversity),
-			Languages:       get(models.ProgrammingLanguages),
-			AppliedAt:       appliedAt,
-			RawRow:          raw,
-		})
-	}
-
-	return parsed, parseErrors, nil
-}
+	appliedAt, err := dates.parse(get(models.FieldAppliedAt))
+	if err != nil {
+		return row, []models.ImportRowError{rowErr(models.FieldAppliedAt, models.RowErrInvalidAppliedAt, fmt.Sprintf("инвалидная дата подачи: %v", err))}, false
+	}
 
-// indexColumns - взятие название столбцов в xlsx
-func indexColumns(header []string) map[string]int {
-	m := make(map[string]int, len(header))
-	for i, h := range header {
-		h = strings.TrimSpace(h)
-		if h == "" {
+	var by *int
+	if s := get(models.FieldBirthYear); s != "" {
+		v, e := dates.year(s)
+		if e != nil {
+			rowErrors = append(rowErrors, rowErr(models.FieldBirthYear, models.RowErrInvalidBirthYear, fmt.Sprintf("инвалидная дата рождения: %v", e)))
+		} else {
+			by = &v
+		}
+	}
+
+	raw := map[string]any{}
+	for j, name := range header {
+		if name == "" || j >= len(r) {
 			continue
 		}
-		m[h] = i
+		raw[name] = r[j]
 	}
-	return m
+
+	return models.ParsedRow{
+		LastName:        lastName,
+		FirstName:       firstName,
+		Email:           email,
+		EmailNormalized: emailNorm,
+		EmailInvalid:    emailInvalid,
+		Phone:           phoneRaw,
+		PhoneNormalized: phoneNorm,
+		Telegram:        telegram,
+		ResumeURL:       get(models.FieldResumeURL),
+		Priority1:       get(models.FieldPriority1),
+		Priority2:       get(models.FieldPriority2),
+		Course:          get(models.FieldCourse),
+		Specialty:       get(models.FieldSpecialty),
+		SpecialtyOther:  get(models.FieldSpecialtyOther),
+		Schedule:        get(models.FieldSchedule),
+		City:            get(models.FieldCity),
+		CityOther:       get(models.FieldCityOther),
+		Source:          get(models.FieldSource),
+		BirthYear:       by,
+		Citizenship:     get(models.FieldCitizenship),
+		University:      get(models.FieldUniversity),
+		UniversityOther: get(models.FieldUniversityOther),
+		Languages:       get(models.FieldLanguages),
+		AppliedAt:       appliedAt,
+		RawRow:          raw,
+	}, rowErrors, true
 }
 
 func allEmpty(r []string) bool {
@@ -208,48 +648,6 @@ func allEmpty(r []string) bool {
 	return true
 }
 
-func normalizePhone(s string) string {
-	s = strings.TrimSpace(s)
-	if s == "" {
-		return ""
-	}
-	// оставим только цифры и +
-	var b strings.Builder
-	for _, ch := range s {
-		if (ch >= '0' && ch <= '9') || ch == '+' {
-			b.WriteRune(ch)
-		}
-	}
-	out := b.String()
-	// если начинается с 8 и длина 11 (RU), например, можно привести к +7...
-	return out
-}
-
-func parseAppliedAt(s string) (time.Time, error) {
-	s = strings.TrimSpace(s)
-	if s == "" {
-		return time.Time{}, errors.New("empty")
-	}
-	// Подстрой под реальный формат в файле.
-	// Часто встречается: "02.01.2006 15:04" или "02.01.2006"
-	layouts := []string{
-		"2006-01-02 15:04:05",
-		"2006-01-02 15:04",
-		"2006-01-02",
-		"02.01.2006 15:04:05",
-		"02.01.2006 15:04",
-		"02.01.2006",
-		time.RFC3339,
-	}
-	for _, l := range layouts {
-		if t, err := time.ParseInLocation(l, s, time.Local); err == nil {
-			// если дата без времени — ставим 00:00 локально
-			return t, nil
-		}
-	}
-	return time.Time{}, fmt.Errorf("неподдерживаемый формат даты: %q", s)
-}
-
 func parseYear(s string) (int, error) {
 	s = strings.TrimSpace(s)
 	if len(s) == 4 {
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+)
+
+// IntakeRejectedError - заявка с формы не прошла разбор или её отвергла БД
+type IntakeRejectedError struct {
+	Errors []models.ImportRowError
+}
+
+func (e *IntakeRejectedError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, re := range e.Errors {
+		msgs = append(msgs, re.Message)
+	}
+	return "intake rejected: " + strings.Join(msgs, "; ")
+}
+
+// IntakeApplication - одна заявка с формы: разбор и нормализация как у строки файла,
+// дубль по external_key не вставляется. Заявки за сутки (по Москве) собираются
+// в один импорт с профилем webhook, его счётчики растут с каждой заявкой.
+// signedAt - время из подписи запроса: повтор того же запроса даёт то же время, а значит
+// тот же applied_at по умолчанию и тот же external_key.
+func (s *Service) IntakeApplication(ctx context.Context, fields map[string]any, signedAt time.Time) (models.IntakeResponse, error) {
+	row, rowErrors, err := intakeRow(fields, signedAt)
+	if err != nil {
+		return models.IntakeResponse{}, err
+	}
+
+	day := signedAt.In(moscow).Format("2006-01-02")
+	name := "webhook " + day
+	sum := sha256.Sum256([]byte(name))
+	importID := uuid.NewSHA1(uuid.NameSpaceURL, []byte("x5-intern-hiring/imports/"+name))
+	err = s.repo.EnsureImport(ctx, &models.FileMetaData{
+		ImportID:    importID,
+		ProfileCode: models.IntakeProfileCode,
+		FileName:    name,
+		FileSha256:  hex.EncodeToString(sum[:]),
+		Status:      models.FileParsed,
+	})
+	if err != nil {
+		return models.IntakeResponse{}, err
+	}
+
+	w, err := s.repo.NewImportWriter(ctx, importID)
+	if err != nil {
+		return models.IntakeResponse{}, err
+	}
+	if err = w.Write(ctx, []models.ParsedRow{row}); err != nil {
+		w.Rollback(ctx)
+		return models.IntakeResponse{}, err
+	}
+	if failures := w.Failures(); len(failures) > 0 {
+		w.Rollback(ctx)
+		return models.IntakeResponse{}, &IntakeRejectedError{Errors: failures}
+	}
+	if err = w.AddImportStats(ctx, 1); err != nil {
+		w.Rollback(ctx)
+		return models.IntakeResponse{}, err
+	}
+	if err = w.Commit(ctx); err != nil {
+		return models.IntakeResponse{}, err
+	}
+
+	return intakeResponse(importID, w.Stats(), rowErrors), nil
+}
+
+// intakeRow - поля заявки как строка файла с профилем webhook
+func intakeRow(fields map[string]any, signedAt time.Time) (models.ParsedRow, []models.ImportRowError, error) {
+	profile := models.IntakeProfile()
+
+	// время подачи не передали — считаем временем подписи запроса, а не получения:
+	// иначе повтор запроса получит другой external_key и станет второй заявкой
+	if v, ok := fields[models.FieldAppliedAt]; !ok || v == nil || strings.TrimSpace(fmt.Sprint(v)) == "" {
+		fields[models.FieldAppliedAt] = signedAt.In(moscow).Format(time.RFC3339)
+	}
+
+	header := make([]string, 0, len(fields))
+	for name := range fields {
+		header = append(header, name)
+	}
+	sort.Strings(header)
+	cells := make([]string, len(header))
+	for i, name := range header {
+		cells[i] = intakeCell(fields[name])
+	}
+
+	col, err := matchColumns(profile, header)
+	if err != nil {
+		return models.ParsedRow{}, nil, err
+	}
+	row, rowErrors, ok := parseRow(col, newDateParser(profile, false), header, cells, 1)
+	if !ok {
+		return models.ParsedRow{}, nil, &IntakeRejectedError{Errors: rowErrors}
+	}
+	row.Row = 1
+	row.Cells = cells
+	return row, rowErrors, nil
+}
+
+// intakeResponse - ответ по итогу записи: ничего не вставлено — заявка уже была (external_key)
+func intakeResponse(importID uuid.UUID, stats models.InsertStats, warnings []models.ImportRowError) models.IntakeResponse {
+	res := models.IntakeResponse{
+		ImportID:         importID.String(),
+		Status:           models.IntakeCreated,
+		CandidateMatched: stats.CandidatesMatched > 0,
+		Warnings:         warnings,
+	}
+	if stats.Inserted == 0 {
+		res.Status = models.IntakeDuplicate
+	}
+	if res.Warnings == nil {
+		res.Warnings = []models.ImportRowError{}
+	}
+	return res
+}
+
+// intakeCell - значение поля JSON как текст ячейки
+func intakeCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case map[string]any, []any:
+		b, _ := json.Marshal(v)
+		return string(b)
+	default:
+		return fmt.Sprint(v)
+	}
+}
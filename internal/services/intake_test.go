@@ -0,0 +1,61 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/repositories"
+)
+
+// повтор подписанного запроса без applied_at: одно время подписи — один external_key,
+// поэтому вторая запись ничего не вставляет и ответ — duplicate
+func TestIntakeReplayIsDuplicate(t *testing.T) {
+	body := []byte(`{"last_name":"Петров","first_name":"Пётр","email":"petrov@example.ru","priority1":"Аналитика"}`)
+	signedAt := time.Unix(1760000000, 0)
+
+	decode := func() map[string]any {
+		var fields map[string]any
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		if err := dec.Decode(&fields); err != nil {
+			t.Fatal(err)
+		}
+		return fields
+	}
+
+	first, _, err := intakeRow(decode(), signedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay, _, err := intakeRow(decode(), signedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repositories.ExternalKey(first) != repositories.ExternalKey(replay) {
+		t.Fatalf("replay external_key differs: applied_at %v vs %v", first.AppliedAt, replay.AppliedAt)
+	}
+	if !first.AppliedAt.Equal(signedAt) {
+		t.Errorf("applied_at = %v, want signature time %v", first.AppliedAt, signedAt)
+	}
+
+	// повтор вставляет 0 строк: дубль отсекает уникальный external_key
+	importID := uuid.New()
+	if res := intakeResponse(importID, models.InsertStats{Inserted: 1}, nil); res.Status != models.IntakeCreated {
+		t.Errorf("first status = %q, want %q", res.Status, models.IntakeCreated)
+	}
+	if res := intakeResponse(importID, models.InsertStats{Skipped: 1}, nil); res.Status != models.IntakeDuplicate {
+		t.Errorf("replay status = %q, want %q", res.Status, models.IntakeDuplicate)
+	}
+
+	later, _, err := intakeRow(decode(), signedAt.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repositories.ExternalKey(later) == repositories.ExternalKey(first) {
+		t.Error("a request signed at another time got the same external_key")
+	}
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"context"
+
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+)
+
+func (s *Service) ListIntegrationAudit(ctx context.Context, p models.ListIntegrationAuditParams) (models.ListIntegrationAuditResponse, error) {
+	// в ответе — те limit/offset, с которыми реально выбирали
+	p.Limit, p.Offset = pageBounds(p.Limit, p.Offset)
+	items, total, err := s.repo.ListIntegrationAudit(ctx, p)
+	if err != nil {
+		return models.ListIntegrationAuditResponse{}, err
+	}
+	return models.ListIntegrationAuditResponse{
+		Items:  items,
+		Total:  total,
+		Limit:  p.Limit,
+		Offset: p.Offset,
+	}, nil
+}
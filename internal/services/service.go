@@ -1,11 +1,45 @@
 package services
 
-import "github.com/kurushqosimi/x5-intern-hiring/internal/repositories"
+import (
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/repositories"
+)
+
+const (
+	importQueueSize   = 16        // сколько файлов может ждать фонового импорта
+	importErrorsLimit = 1000      // сколько ошибок разбора отдаём в деталях импорта
+	dryRunSampleSize  = 20        // сколько нормализованных строк показываем в dry_run
+	importBatchSize   = 500       // строк в одной пачке вставки
+	maxUploadSize     = 200 << 20 // загрузка пишется во временный файл, а не в память
+
+	insertImportAttempts = 3 // вставок записи импорта, если индекс по sha256 занят уже завершившимся импортом
+)
 
 type Service struct {
-	repo *repositories.Repository
+	repo       *repositories.Repository
+	importJobs chan importJob
+	owner      string // пишется в imports.owner, см. FailInterruptedImports
 }
 
 func NewService(repo *repositories.Repository) *Service {
-	return &Service{repo: repo}
+	return &Service{repo: repo, importJobs: make(chan importJob, importQueueSize), owner: models.ImportOwnerAPI}
+}
+
+// SetImportOwner - чьи импорты создаёт этот сервис; по умолчанию ImportOwnerAPI
+func (s *Service) SetImportOwner(owner string) {
+	s.owner = owner
+}
+
+// pageBounds - limit/offset списков: по умолчанию 50, не больше 200
+func pageBounds(limit, offset int) (int, int) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
 }
@@ -0,0 +1,169 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"github.com/kurushqosimi/x5-intern-hiring/pkg/crm"
+	"go.uber.org/zap"
+)
+
+// CRMClient - адаптер конкретной CRM (crm.HTTPClient; в тестах crm.FakeClient)
+type CRMClient interface {
+	Push(ctx context.Context, rec crm.Record) (crm.Response, error)
+}
+
+// CRMOutboxStore - работа диспетчера с БД (repositories.Repository)
+type CRMOutboxStore interface {
+	ClaimPendingCRM(ctx context.Context, limit int, lockFor time.Duration) ([]models.OutboxCRM, error)
+	MarkCRMSynced(ctx context.Context, crmID, appID uuid.UUID, externalID string) error
+	MarkCRMFailed(ctx context.Context, crmID uuid.UUID, lastError string, retryAt time.Time) error
+	MarkCRMDead(ctx context.Context, crmID uuid.UUID, lastError string) error
+	InsertIntegrationAudit(ctx context.Context, a models.IntegrationAudit) error
+}
+
+type CRMDispatcherConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	LockFor      time.Duration
+	Retry        RetryPolicy
+}
+
+// CRMDispatcher - доставляет crm_outbox в CRM
+type CRMDispatcher struct {
+	logger *zap.Logger
+	repo   CRMOutboxStore
+	client CRMClient
+	cfg    CRMDispatcherConfig
+}
+
+func NewCRMDispatcher(logger *zap.Logger, repo CRMOutboxStore, client CRMClient, cfg CRMDispatcherConfig) *CRMDispatcher {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 5 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 20
+	}
+	if cfg.LockFor <= 0 {
+		cfg.LockFor = 2 * time.Minute
+	}
+	if cfg.Retry.BaseDelay <= 0 {
+		cfg.Retry = DefaultRetryPolicy()
+	}
+	return &CRMDispatcher{logger: logger, repo: repo, client: client, cfg: cfg}
+}
+
+// Run - крутится до отмены ctx
+func (d *CRMDispatcher) Run(ctx context.Context) {
+	d.logger.Info("crm_dispatcher_started")
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for d.processBatch(ctx) > 0 {
+			if ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			d.logger.Info("crm_dispatcher_stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *CRMDispatcher) processBatch(ctx context.Context) int {
+	recs, err := d.repo.ClaimPendingCRM(ctx, d.cfg.BatchSize, d.cfg.LockFor)
+	if err != nil {
+		if ctx.Err() == nil {
+			d.logger.Error("d.repo.ClaimPendingCRM: ", zap.Error(err))
+		}
+		return 0
+	}
+
+	for _, r := range recs {
+		d.process(ctx, r)
+	}
+	return len(recs)
+}
+
+func (d *CRMDispatcher) process(ctx context.Context, r models.OutboxCRM) {
+	resp, err := d.client.Push(ctx, crm.Record{ID: r.CRMID.String(), Payload: r.Payload})
+	d.audit(ctx, r, resp, err)
+	if err == nil {
+		if err = d.repo.MarkCRMSynced(ctx, r.CRMID, r.ApplicationID, resp.ExternalID); err != nil {
+			d.logger.Error("d.repo.MarkCRMSynced: ", zap.Error(err), zap.String("crm_id", r.CRMID.String()))
+		}
+		return
+	}
+
+	attempts := r.Attempt + 1
+	var statusErr *crm.StatusError
+	if d.cfg.Retry.Exhausted(attempts) || (errors.As(err, &statusErr) && statusErr.Permanent()) {
+		d.logger.Error("crm_dead",
+			zap.String("crm_id", r.CRMID.String()),
+			zap.String("application_id", r.ApplicationID.String()),
+			zap.Int("attempt", attempts),
+			zap.Error(err),
+		)
+		if markErr := d.repo.MarkCRMDead(ctx, r.CRMID, err.Error()); markErr != nil {
+			d.logger.Error("d.repo.MarkCRMDead: ", zap.Error(markErr), zap.String("crm_id", r.CRMID.String()))
+		}
+		return
+	}
+
+	delay := d.cfg.Retry.NextDelay(attempts)
+	d.logger.Warn("crm_push_failed",
+		zap.String("crm_id", r.CRMID.String()),
+		zap.Int("attempt", attempts),
+		zap.Duration("retry_in", delay),
+		zap.Error(err),
+	)
+	if markErr := d.repo.MarkCRMFailed(ctx, r.CRMID, err.Error(), time.Now().Add(delay)); markErr != nil {
+		d.logger.Error("d.repo.MarkCRMFailed: ", zap.Error(markErr), zap.String("crm_id", r.CRMID.String()))
+	}
+}
+
+// audit - сохраняем отправленный payload и ответ CRM в integration_audit
+func (d *CRMDispatcher) audit(ctx context.Context, r models.OutboxCRM, resp crm.Response, pushErr error) {
+	a := models.IntegrationAudit{
+		System:   models.AuditSystemCRM,
+		EntityID: r.ApplicationID,
+		Request: map[string]any{
+			"crm_id":  r.CRMID.String(),
+			"attempt": r.Attempt + 1,
+			"payload": json.RawMessage(r.Payload),
+		},
+	}
+	if resp.StatusCode > 0 {
+		status := resp.StatusCode
+		a.HTTPStatus = &status
+	}
+
+	// ответ CRM не обязан быть json — тогда сохраняем как строку
+	response := map[string]any{}
+	if len(resp.Body) > 0 {
+		if json.Valid(resp.Body) {
+			response["body"] = json.RawMessage(resp.Body)
+		} else {
+			response["body"] = string(resp.Body)
+		}
+	}
+	if pushErr != nil {
+		response["error"] = pushErr.Error()
+	}
+	if len(response) > 0 {
+		a.Response = response
+	}
+
+	if err := d.repo.InsertIntegrationAudit(ctx, a); err != nil {
+		d.logger.Error("d.repo.InsertIntegrationAudit: ", zap.Error(err), zap.String("crm_id", r.CRMID.String()))
+	}
+}
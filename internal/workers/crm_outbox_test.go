@@ -0,0 +1,132 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"github.com/kurushqosimi/x5-intern-hiring/pkg/crm"
+	"go.uber.org/zap"
+)
+
+// memCRMOutbox - crm_outbox в памяти
+type memCRMOutbox struct {
+	mu      sync.Mutex
+	pending []models.OutboxCRM
+	synced  map[uuid.UUID]string // crm_id -> external_id
+	failed  map[uuid.UUID]string
+	dead    map[uuid.UUID]string
+	audits  []models.IntegrationAudit
+}
+
+func newMemCRMOutbox(recs ...models.OutboxCRM) *memCRMOutbox {
+	return &memCRMOutbox{
+		pending: recs,
+		synced:  map[uuid.UUID]string{},
+		failed:  map[uuid.UUID]string{},
+		dead:    map[uuid.UUID]string{},
+	}
+}
+
+func (m *memCRMOutbox) ClaimPendingCRM(_ context.Context, limit int, _ time.Duration) ([]models.OutboxCRM, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := min(limit, len(m.pending))
+	out := m.pending[:n:n]
+	m.pending = m.pending[n:]
+	return out, nil
+}
+
+func (m *memCRMOutbox) MarkCRMSynced(_ context.Context, crmID, _ uuid.UUID, externalID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.synced[crmID] = externalID
+	return nil
+}
+
+func (m *memCRMOutbox) MarkCRMFailed(_ context.Context, crmID uuid.UUID, lastError string, _ time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failed[crmID] = lastError
+	return nil
+}
+
+func (m *memCRMOutbox) MarkCRMDead(_ context.Context, crmID uuid.UUID, lastError string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dead[crmID] = lastError
+	return nil
+}
+
+func (m *memCRMOutbox) InsertIntegrationAudit(_ context.Context, a models.IntegrationAudit) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.audits = append(m.audits, a)
+	return nil
+}
+
+func outboxRecord(attempt int) models.OutboxCRM {
+	return models.OutboxCRM{
+		CRMID:         uuid.New(),
+		ApplicationID: uuid.New(),
+		Payload:       []byte(`{"application_id":"x"}`),
+		Attempt:       attempt,
+	}
+}
+
+func TestCRMDispatcherProcessBatch(t *testing.T) {
+	ok := outboxRecord(0)
+	transient := outboxRecord(0)
+	rejected := outboxRecord(0)
+	exhausted := outboxRecord(2)
+
+	client := crm.NewFakeClient()
+	client.Fail = func(rec crm.Record) error {
+		switch rec.ID {
+		case transient.CRMID.String():
+			return errors.New("connection reset")
+		case rejected.CRMID.String():
+			return &crm.StatusError{StatusCode: http.StatusUnprocessableEntity, Body: "bad payload"}
+		case exhausted.CRMID.String():
+			return &crm.StatusError{StatusCode: http.StatusBadGateway}
+		}
+		return nil
+	}
+
+	store := newMemCRMOutbox(ok, transient, rejected, exhausted)
+	d := NewCRMDispatcher(zap.NewNop(), store, client, CRMDispatcherConfig{
+		BatchSize: 10,
+		Retry:     RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: time.Minute},
+	})
+
+	if n := d.processBatch(context.Background()); n != 4 {
+		t.Fatalf("processBatch = %d, want 4", n)
+	}
+
+	if ext, found := store.synced[ok.CRMID]; !found || ext == "" {
+		t.Errorf("record pushed to CRM is not synced: %v", store.synced)
+	}
+	if _, found := client.Records()[ok.CRMID.String()]; !found {
+		t.Errorf("CRM did not receive record %s", ok.CRMID)
+	}
+	if _, found := store.failed[transient.CRMID]; !found {
+		t.Errorf("transient error must be retried: failed=%v", store.failed)
+	}
+	if _, found := store.dead[rejected.CRMID]; !found {
+		t.Errorf("4xx from CRM must be dead: dead=%v", store.dead)
+	}
+	if _, found := store.dead[exhausted.CRMID]; !found {
+		t.Errorf("record out of attempts must be dead: dead=%v", store.dead)
+	}
+	if len(store.synced) != 1 || len(store.failed) != 1 || len(store.dead) != 2 {
+		t.Errorf("synced=%d failed=%d dead=%d, want 1/1/2", len(store.synced), len(store.failed), len(store.dead))
+	}
+	if len(store.audits) != 4 {
+		t.Errorf("audits = %d, want one per push", len(store.audits))
+	}
+}
@@ -0,0 +1,184 @@
+package workers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/repositories"
+	"github.com/kurushqosimi/x5-intern-hiring/pkg/mailer"
+	"go.uber.org/zap"
+)
+
+// EmailSender - канал доставки писем (SMTP, внешний провайдер, заглушка в тестах).
+// Возвращает идентификатор сообщения у провайдера.
+type EmailSender interface {
+	Send(ctx context.Context, msg mailer.Message) (string, error)
+}
+
+type EmailWorkerConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	LockFor      time.Duration // сколько письмо считается захваченным воркером
+	Retry        RetryPolicy
+}
+
+// EmailWorker - разбирает email_outbox и отправляет письма
+type EmailWorker struct {
+	logger *zap.Logger
+	repo   *repositories.Repository
+	sender EmailSender
+	cfg    EmailWorkerConfig
+}
+
+func NewEmailWorker(logger *zap.Logger, repo *repositories.Repository, sender EmailSender, cfg EmailWorkerConfig) *EmailWorker {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 5 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 20
+	}
+	if cfg.LockFor <= 0 {
+		cfg.LockFor = 2 * time.Minute
+	}
+	if cfg.Retry.BaseDelay <= 0 {
+		cfg.Retry = DefaultRetryPolicy()
+	}
+	return &EmailWorker{logger: logger, repo: repo, sender: sender, cfg: cfg}
+}
+
+// Run - крутится до отмены ctx
+func (w *EmailWorker) Run(ctx context.Context) {
+	w.logger.Info("email_worker_started")
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// разбираем очередь, пока она не опустеет, потом ждём следующий тик
+		for w.processBatch(ctx) > 0 {
+			if ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			w.logger.Info("email_worker_stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *EmailWorker) processBatch(ctx context.Context) int {
+	emails, err := w.repo.ClaimPendingEmails(ctx, w.cfg.BatchSize, w.cfg.LockFor)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.logger.Error("w.repo.ClaimPendingEmails: ", zap.Error(err))
+		}
+		return 0
+	}
+
+	for _, e := range emails {
+		w.process(ctx, e)
+	}
+	return len(emails)
+}
+
+func (w *EmailWorker) process(ctx context.Context, e models.OutboxEmail) {
+	msg, err := renderEmail(e)
+	if err != nil {
+		// битый шаблон или нет переменной — повтор не поможет
+		w.dead(ctx, e, err)
+		return
+	}
+
+	providerID, err := w.sender.Send(ctx, msg)
+	w.audit(ctx, e, msg, providerID, err)
+	if err == nil {
+		if err = w.repo.MarkEmailSent(ctx, e.EmailID, e.ApplicationID, providerID); err != nil {
+			w.logger.Error("w.repo.MarkEmailSent: ", zap.Error(err), zap.String("email_id", e.EmailID.String()))
+		}
+		return
+	}
+
+	attempts := e.Attempt + 1
+	if w.cfg.Retry.Exhausted(attempts) {
+		w.dead(ctx, e, err)
+		return
+	}
+
+	delay := w.cfg.Retry.NextDelay(attempts)
+	w.logger.Warn("email_send_failed",
+		zap.String("email_id", e.EmailID.String()),
+		zap.Int("attempt", attempts),
+		zap.Duration("retry_in", delay),
+		zap.Error(err),
+	)
+	if markErr := w.repo.MarkEmailFailed(ctx, e.EmailID, err.Error(), time.Now().Add(delay)); markErr != nil {
+		w.logger.Error("w.repo.MarkEmailFailed: ", zap.Error(markErr), zap.String("email_id", e.EmailID.String()))
+	}
+}
+
+func (w *EmailWorker) dead(ctx context.Context, e models.OutboxEmail, cause error) {
+	w.logger.Error("email_dead",
+		zap.String("email_id", e.EmailID.String()),
+		zap.String("application_id", e.ApplicationID.String()),
+		zap.Int("attempt", e.Attempt+1),
+		zap.Error(cause),
+	)
+	if err := w.repo.MarkEmailDead(ctx, e.EmailID, cause.Error()); err != nil {
+		w.logger.Error("w.repo.MarkEmailDead: ", zap.Error(err), zap.String("email_id", e.EmailID.String()))
+	}
+}
+
+// audit - фиксируем в integration_audit, что именно ушло кандидату
+func (w *EmailWorker) audit(ctx context.Context, e models.OutboxEmail, msg mailer.Message, providerID string, sendErr error) {
+	resp := map[string]any{"provider_message_id": providerID}
+	if sendErr != nil {
+		resp = map[string]any{"error": sendErr.Error()}
+	}
+	err := w.repo.InsertIntegrationAudit(ctx, models.IntegrationAudit{
+		System:   models.AuditSystemEmail,
+		EntityID: e.ApplicationID,
+		Request: map[string]any{
+			"email_id": e.EmailID.String(),
+			"attempt":  e.Attempt + 1,
+			"to":       msg.To,
+			"subject":  msg.Subject,
+			"body":     msg.Body,
+		},
+		Response: resp,
+	})
+	if err != nil {
+		w.logger.Error("w.repo.InsertIntegrationAudit: ", zap.Error(err), zap.String("email_id", e.EmailID.String()))
+	}
+}
+
+// renderEmail - подставляет render_vars в subject и body шаблона ({{.first_name}} и т.п.)
+func renderEmail(e models.OutboxEmail) (mailer.Message, error) {
+	subject, err := renderText(e.Subject, e.RenderVars)
+	if err != nil {
+		return mailer.Message{}, fmt.Errorf("render subject: %w", err)
+	}
+	body, err := renderText(e.Body, e.RenderVars)
+	if err != nil {
+		return mailer.Message{}, fmt.Errorf("render body: %w", err)
+	}
+	return mailer.Message{To: e.ToEmail, Subject: subject, Body: body}, nil
+}
+
+func renderText(text string, vars map[string]any) (string, error) {
+	tpl, err := template.New("email").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err = tpl.Execute(&b, vars); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
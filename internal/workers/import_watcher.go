@@ -0,0 +1,193 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kurushqosimi/x5-intern-hiring/internal/custom_errors"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"go.uber.org/zap"
+)
+
+const (
+	watchProcessedDir = "processed"
+	watchFailedDir    = "failed"
+)
+
+// FileImporter - синхронный импорт файла с диска (services.Service.ProcessFile)
+type FileImporter interface {
+	ProcessFile(ctx context.Context, path string, opts models.ImportOptions) (*models.XLSXProcRes, error)
+}
+
+type ImportWatcherConfig struct {
+	Dir          string
+	PollInterval time.Duration
+	Options      models.ImportOptions // профиль, листы и т.п. — как при загрузке через API
+}
+
+// ImportWatcher - забирает .xlsx/.csv из каталога и импортирует их.
+// Файл берётся, когда его размер и время изменения не менялись между двумя опросами
+// (иначе можно прочитать недокопированный файл), после импорта переносится в processed/,
+// а если сам файл не годится для импорта — в failed/. При остановке или сбое БД файл
+// остаётся на месте и импортируется на следующем опросе.
+type ImportWatcher struct {
+	logger   *zap.Logger
+	importer FileImporter
+	cfg      ImportWatcherConfig
+
+	seen map[string]fileState // состояние файлов на прошлом опросе
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+func NewImportWatcher(logger *zap.Logger, importer FileImporter, cfg ImportWatcherConfig) (*ImportWatcher, error) {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 10 * time.Second
+	}
+	for _, sub := range []string{watchProcessedDir, watchFailedDir} {
+		if err := os.MkdirAll(filepath.Join(cfg.Dir, sub), 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return &ImportWatcher{logger: logger, importer: importer, cfg: cfg, seen: map[string]fileState{}}, nil
+}
+
+// Run - крутится до отмены ctx
+func (w *ImportWatcher) Run(ctx context.Context) {
+	w.logger.Info("import_watcher_started", zap.String("dir", w.cfg.Dir))
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.Scan(ctx)
+
+		select {
+		case <-ctx.Done():
+			w.logger.Info("import_watcher_stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan - один проход по каталогу; возвращает число импортированных (в т.ч. неудачно) файлов
+func (w *ImportWatcher) Scan(ctx context.Context) int {
+	entries, err := os.ReadDir(w.cfg.Dir)
+	if err != nil {
+		w.logger.Error("os.ReadDir: ", zap.String("dir", w.cfg.Dir), zap.Error(err))
+		return 0
+	}
+
+	current := make(map[string]fileState, len(entries))
+	n := 0
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		name := e.Name()
+		if e.IsDir() || !watchable(name) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		st := fileState{size: info.Size(), modTime: info.ModTime()}
+		if prev, ok := w.seen[name]; !ok || prev != st {
+			// новый или ещё копируется — ждём следующего опроса
+			current[name] = st
+			continue
+		}
+
+		w.importFile(ctx, name)
+		n++
+	}
+	w.seen = current
+	return n
+}
+
+func (w *ImportWatcher) importFile(ctx context.Context, name string) {
+	path := filepath.Join(w.cfg.Dir, name)
+	log := w.logger.With(zap.String("file", name))
+
+	res, err := w.importer.ProcessFile(ctx, path, w.cfg.Options)
+	if err != nil {
+		var dup *custom_errors.DuplicateImportError
+		switch {
+		case ctx.Err() != nil:
+			log.Info("import_watcher_interrupted", zap.Error(err))
+		case errors.As(err, &dup):
+			log.Warn("import_watcher_duplicate", zap.String("import_id", dup.ImportID), zap.String("status", dup.Status))
+			w.move(log, path, watchFailedDir)
+		case badFile(err):
+			log.Error("import_watcher_failed", zap.Error(err))
+			w.move(log, path, watchFailedDir)
+		default:
+			log.Error("import_watcher_retry_later", zap.Error(err))
+		}
+		return
+	}
+
+	log.Info("import_watcher_imported",
+		zap.String("import_id", res.ImportId),
+		zap.Int("total_rows", res.TotalRows),
+		zap.Int("inserted_rows", res.InsertedRows),
+		zap.Int("skipped_rows", res.SkippedRows),
+		zap.Int("failed_rows", res.FailedRows),
+		zap.Int("errors", len(res.Errors)),
+	)
+	w.move(log, path, watchProcessedDir)
+}
+
+// move - в подкаталог; одноимённый файл там не перезаписывается
+func (w *ImportWatcher) move(log *zap.Logger, path, sub string) {
+	dst := filepath.Join(w.cfg.Dir, sub, filepath.Base(path))
+	if _, err := os.Stat(dst); err == nil {
+		ext := filepath.Ext(dst)
+		dst = fmt.Sprintf("%s_%s%s", strings.TrimSuffix(dst, ext), time.Now().Format("20060102_150405"), ext)
+	}
+	if err := os.Rename(path, dst); err != nil {
+		log.Error("os.Rename: ", zap.String("to", dst), zap.Error(err))
+	}
+}
+
+// badFile - ошибка в самом файле: формат, заголовок, листы. Повторный импорт не поможет
+func badFile(err error) bool {
+	for _, target := range []error{
+		custom_errors.ErrInvalidXLSX,
+		custom_errors.ErrInvalidCSV,
+		custom_errors.ErrUnsupportedFormat,
+		custom_errors.ErrNoXLSXSheets,
+		custom_errors.ErrNoXLSXData,
+		custom_errors.ErrFileTooLarge,
+		custom_errors.ErrMissingColumns,
+		custom_errors.ErrSheetNotFound,
+		custom_errors.ErrNoMatchingSheets,
+		custom_errors.ErrSheetsNotApplicable,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// watchable - выгрузки xlsx/xlsm/csv/tsv; временные файлы Excel (~$...) и скрытые пропускаем
+func watchable(name string) bool {
+	if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "~$") {
+		return false
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".xlsx", ".xlsm", ".csv", ".tsv":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kurushqosimi/x5-intern-hiring/internal/custom_errors"
+	"github.com/kurushqosimi/x5-intern-hiring/internal/models"
+	"go.uber.org/zap"
+)
+
+// stubImporter - ошибка импорта по имени файла
+type stubImporter struct {
+	errs map[string]error
+}
+
+func (s stubImporter) ProcessFile(_ context.Context, path string, _ models.ImportOptions) (*models.XLSXProcRes, error) {
+	if err := s.errs[filepath.Base(path)]; err != nil {
+		return nil, err
+	}
+	return &models.XLSXProcRes{}, nil
+}
+
+func TestImportWatcherMovesFiles(t *testing.T) {
+	dir := t.TempDir()
+	importer := stubImporter{errs: map[string]error{
+		"broken.xlsx":    fmt.Errorf("%w: zip: not a valid zip file", custom_errors.ErrInvalidXLSX),
+		"columns.csv":    &custom_errors.MissingColumnsError{Columns: []string{"Почта"}},
+		"duplicate.xlsx": &custom_errors.DuplicateImportError{ImportID: "x", Status: models.FileParsed},
+		"db-down.xlsx":   errors.New("failed to connect to `host=db`"),
+	}}
+	files := []string{"ok.xlsx", "macro.xlsm", "broken.xlsx", "columns.csv", "duplicate.xlsx", "db-down.xlsx", "~$ok.xlsx"}
+	for _, name := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w, err := NewImportWatcher(zap.NewNop(), importer, ImportWatcherConfig{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	// первый опрос только запоминает файлы
+	if n := w.Scan(ctx); n != 0 {
+		t.Fatalf("first scan imported %d files", n)
+	}
+	if n := w.Scan(ctx); n != 6 {
+		t.Fatalf("second scan imported %d files, want 6", n)
+	}
+
+	want := map[string]string{
+		"ok.xlsx":        watchProcessedDir,
+		"macro.xlsm":     watchProcessedDir,
+		"broken.xlsx":    watchFailedDir,
+		"columns.csv":    watchFailedDir,
+		"duplicate.xlsx": watchFailedDir,
+		"db-down.xlsx":   "",
+		"~$ok.xlsx":      "",
+	}
+	for name, sub := range want {
+		if _, err := os.Stat(filepath.Join(dir, sub, name)); err != nil {
+			t.Errorf("%s: want in %q: %v", name, sub, err)
+		}
+	}
+}
+
+func TestImportWatcherKeepsFileOnCancel(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	importer := cancelImporter{cancel: cancel}
+	if err := os.WriteFile(filepath.Join(dir, "big.xlsx"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewImportWatcher(zap.NewNop(), importer, ImportWatcherConfig{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Scan(ctx)
+	w.Scan(ctx)
+
+	if _, err = os.Stat(filepath.Join(dir, "big.xlsx")); err != nil {
+		t.Errorf("interrupted file was moved: %v", err)
+	}
+}
+
+// cancelImporter - остановка процесса посреди импорта
+type cancelImporter struct {
+	cancel context.CancelFunc
+}
+
+func (c cancelImporter) ProcessFile(ctx context.Context, _ string, _ models.ImportOptions) (*models.XLSXProcRes, error) {
+	c.cancel()
+	return nil, fmt.Errorf("%w: %v", custom_errors.ErrInvalidXLSX, ctx.Err())
+}
@@ -0,0 +1,44 @@
+package workers
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy - экспоненциальный backoff с джиттером для outbox-воркеров
+type RetryPolicy struct {
+	MaxAttempts int           // после стольких неудачных попыток запись уходит в DEAD
+	BaseDelay   time.Duration // задержка после первой неудачи
+	MaxDelay    time.Duration // потолок задержки
+	Jitter      float64       // 0..1 — доля случайного разброса задержки
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 8,
+		BaseDelay:   30 * time.Second,
+		MaxDelay:    6 * time.Hour,
+		Jitter:      0.2,
+	}
+}
+
+// Exhausted - попытки кончились (attempts — сколько попыток уже сделано, включая текущую)
+func (p RetryPolicy) Exhausted(attempts int) bool {
+	return p.MaxAttempts > 0 && attempts >= p.MaxAttempts
+}
+
+// NextDelay - задержка перед следующей попыткой: BaseDelay * 2^(attempts-1) ± Jitter
+func (p RetryPolicy) NextDelay(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	d := float64(p.BaseDelay) * math.Pow(2, float64(attempts-1))
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+BEGIN;
+
+ALTER TABLE crm_outbox
+    ADD COLUMN IF NOT EXISTS locked_until timestamptz NULL,
+    ADD COLUMN IF NOT EXISTS external_id  text NULL;
+
+CREATE INDEX IF NOT EXISTS ix_crm_outbox_locked_until
+    ON crm_outbox(locked_until)
+    WHERE locked_until IS NOT NULL;
+
+COMMIT;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+BEGIN;
+
+DROP INDEX IF EXISTS ix_crm_outbox_locked_until;
+
+ALTER TABLE crm_outbox
+    DROP COLUMN IF EXISTS external_id,
+    DROP COLUMN IF EXISTS locked_until;
+
+COMMIT;
+-- +goose StatementEnd
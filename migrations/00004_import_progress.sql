@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+BEGIN;
+
+ALTER TABLE imports
+    ADD COLUMN IF NOT EXISTS processed_rows int         NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS error          text        NULL,
+    ADD COLUMN IF NOT EXISTS started_at     timestamptz NULL,
+    ADD COLUMN IF NOT EXISTS finished_at    timestamptz NULL;
+
+CREATE TABLE IF NOT EXISTS import_errors (
+    error_id   uuid PRIMARY KEY,
+    import_id  uuid NOT NULL,
+    row_num    int  NOT NULL,
+    message    text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS ix_import_errors_import
+    ON import_errors(import_id, row_num);
+
+COMMIT;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+BEGIN;
+
+DROP INDEX IF EXISTS ix_import_errors_import;
+DROP TABLE IF EXISTS import_errors;
+
+ALTER TABLE imports
+    DROP COLUMN IF EXISTS finished_at,
+    DROP COLUMN IF EXISTS started_at,
+    DROP COLUMN IF EXISTS error,
+    DROP COLUMN IF EXISTS processed_rows;
+
+COMMIT;
+-- +goose StatementEnd
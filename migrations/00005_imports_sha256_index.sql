@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS ix_imports_file_sha256
+    ON imports(file_sha256, created_at DESC);
+
+COMMIT;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+BEGIN;
+
+DROP INDEX IF EXISTS ix_imports_file_sha256;
+
+COMMIT;
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS import_profiles (
+    profile_id uuid PRIMARY KEY,
+    code       text  NOT NULL,
+    name       text  NOT NULL,
+    columns    jsonb NOT NULL,
+    is_default bool  NOT NULL DEFAULT false,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_import_profiles_code
+    ON import_profiles(code);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_import_profiles_default
+    ON import_profiles(is_default)
+    WHERE is_default = true;
+
+ALTER TABLE imports
+    ADD COLUMN IF NOT EXISTS profile_code text NULL;
+
+COMMIT;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+BEGIN;
+
+ALTER TABLE imports
+    DROP COLUMN IF EXISTS profile_code;
+
+DROP INDEX IF EXISTS ux_import_profiles_default;
+DROP INDEX IF EXISTS ux_import_profiles_code;
+DROP TABLE IF EXISTS import_profiles;
+
+COMMIT;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+BEGIN;
+
+ALTER TABLE imports
+    ADD COLUMN IF NOT EXISTS candidates_created int NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS candidates_matched int NOT NULL DEFAULT 0;
+
+COMMIT;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+BEGIN;
+
+ALTER TABLE imports
+    DROP COLUMN IF EXISTS candidates_matched,
+    DROP COLUMN IF EXISTS candidates_created;
+
+COMMIT;
+-- +goose StatementEnd
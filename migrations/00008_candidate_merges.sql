@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS candidate_merges (
+    merge_id            uuid PRIMARY KEY,
+    source_candidate_id uuid  NOT NULL,
+    target_candidate_id uuid  NOT NULL,
+    source_snapshot     jsonb NOT NULL,
+    applications_moved  int   NOT NULL DEFAULT 0,
+    contacts_moved      int   NOT NULL DEFAULT 0,
+    merged_by           uuid  NULL,
+    created_at          timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS ix_candidate_merges_source
+    ON candidate_merges(source_candidate_id);
+
+CREATE INDEX IF NOT EXISTS ix_candidate_merges_target
+    ON candidate_merges(target_candidate_id);
+
+COMMIT;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+BEGIN;
+
+DROP INDEX IF EXISTS ix_candidate_merges_target;
+DROP INDEX IF EXISTS ix_candidate_merges_source;
+DROP TABLE IF EXISTS candidate_merges;
+
+COMMIT;
+-- +goose StatementEnd
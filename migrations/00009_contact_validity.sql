@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+BEGIN;
+
+ALTER TABLE candidate_contacts
+    ADD COLUMN IF NOT EXISTS is_invalid     bool NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS invalid_reason text NULL;
+
+COMMIT;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+BEGIN;
+
+ALTER TABLE candidate_contacts
+    DROP COLUMN IF EXISTS invalid_reason,
+    DROP COLUMN IF EXISTS is_invalid;
+
+COMMIT;
+-- +goose StatementEnd
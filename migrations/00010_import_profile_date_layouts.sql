@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+BEGIN;
+
+ALTER TABLE import_profiles
+    ADD COLUMN IF NOT EXISTS date_layouts text[] NOT NULL DEFAULT '{}';
+
+COMMIT;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+BEGIN;
+
+ALTER TABLE import_profiles
+    DROP COLUMN IF EXISTS date_layouts;
+
+COMMIT;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+BEGIN;
+
+ALTER TABLE imports
+    ADD COLUMN IF NOT EXISTS sheets jsonb NULL;
+
+ALTER TABLE import_errors
+    ADD COLUMN IF NOT EXISTS sheet text NULL;
+
+COMMIT;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+BEGIN;
+
+ALTER TABLE import_errors
+    DROP COLUMN IF EXISTS sheet;
+
+ALTER TABLE imports
+    DROP COLUMN IF EXISTS sheets;
+
+COMMIT;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+BEGIN;
+
+-- заголовки листов файла: {"<лист>": ["Фамилия", ...]}, для csv ключ ""
+ALTER TABLE imports
+    ADD COLUMN IF NOT EXISTS headers jsonb NULL;
+
+ALTER TABLE import_errors
+    ADD COLUMN IF NOT EXISTS column_name text    NULL,
+    ADD COLUMN IF NOT EXISTS code        text    NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS rejected    boolean NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS cells       text[]  NULL;
+
+COMMIT;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+BEGIN;
+
+ALTER TABLE import_errors
+    DROP COLUMN IF EXISTS cells,
+    DROP COLUMN IF EXISTS rejected,
+    DROP COLUMN IF EXISTS code,
+    DROP COLUMN IF EXISTS column_name;
+
+ALTER TABLE imports
+    DROP COLUMN IF EXISTS headers;
+
+COMMIT;
+-- +goose StatementEnd
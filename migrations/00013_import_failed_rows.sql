@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+BEGIN;
+
+-- строки, которые разобрались, но не были приняты БД (импорт в статусе PARTIAL)
+ALTER TABLE imports
+    ADD COLUMN IF NOT EXISTS failed_rows int NOT NULL DEFAULT 0;
+
+COMMIT;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+BEGIN;
+
+ALTER TABLE imports
+    DROP COLUMN IF EXISTS failed_rows;
+
+COMMIT;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+BEGIN;
+
+ALTER TABLE imports
+    ADD COLUMN IF NOT EXISTS reverted_at timestamptz NULL;
+
+COMMIT;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+BEGIN;
+
+ALTER TABLE imports
+    DROP COLUMN IF EXISTS reverted_at;
+
+COMMIT;
+-- +goose StatementEnd
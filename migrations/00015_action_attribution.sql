@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+BEGIN;
+
+-- кто выполнил действие; NULL — система (webhook, import-watch) или до появления пользователей
+ALTER TABLE imports
+    ADD COLUMN IF NOT EXISTS reverted_by uuid NULL;
+
+ALTER TABLE applications
+    ADD COLUMN IF NOT EXISTS status_changed_by uuid NULL;
+
+ALTER TABLE email_outbox
+    ADD COLUMN IF NOT EXISTS created_by uuid NULL;
+
+ALTER TABLE crm_outbox
+    ADD COLUMN IF NOT EXISTS created_by uuid NULL;
+
+CREATE INDEX IF NOT EXISTS ix_imports_uploaded_by
+    ON imports(uploaded_by, created_at DESC)
+    WHERE uploaded_by IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS ix_applications_status_changed_by
+    ON applications(status_changed_by)
+    WHERE status_changed_by IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS ix_email_outbox_created_by
+    ON email_outbox(created_by)
+    WHERE created_by IS NOT NULL;
+
+COMMIT;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+BEGIN;
+
+DROP INDEX IF EXISTS ix_email_outbox_created_by;
+DROP INDEX IF EXISTS ix_applications_status_changed_by;
+DROP INDEX IF EXISTS ix_imports_uploaded_by;
+
+ALTER TABLE crm_outbox
+    DROP COLUMN IF EXISTS created_by;
+
+ALTER TABLE email_outbox
+    DROP COLUMN IF EXISTS created_by;
+
+ALTER TABLE applications
+    DROP COLUMN IF EXISTS status_changed_by;
+
+ALTER TABLE imports
+    DROP COLUMN IF EXISTS reverted_by;
+
+COMMIT;
+-- +goose StatementEnd
@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS users (
+    user_id    uuid PRIMARY KEY,
+    email      text NOT NULL,
+    name       text NOT NULL DEFAULT '',
+    is_active  bool NOT NULL DEFAULT true,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_users_email
+    ON users(lower(email));
+
+-- сам ключ не хранится: только sha256 и первые символы, чтобы отличать ключи в списке
+CREATE TABLE IF NOT EXISTS api_keys (
+    key_id       uuid PRIMARY KEY,
+    user_id      uuid NOT NULL,
+    name         text NOT NULL,
+    prefix       text NOT NULL,
+    key_hash     text NOT NULL,
+    expires_at   timestamptz NULL,
+    last_used_at timestamptz NULL,
+    revoked_at   timestamptz NULL,
+    created_at   timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_api_keys_hash
+    ON api_keys(key_hash);
+
+CREATE INDEX IF NOT EXISTS ix_api_keys_user
+    ON api_keys(user_id, created_at DESC);
+
+COMMIT;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+BEGIN;
+
+DROP INDEX IF EXISTS ix_api_keys_user;
+DROP INDEX IF EXISTS ux_api_keys_hash;
+DROP TABLE IF EXISTS api_keys;
+
+DROP INDEX IF EXISTS ux_users_email;
+DROP TABLE IF EXISTS users;
+
+COMMIT;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+BEGIN;
+
+-- force=true при загрузке: осознанный повторный импорт того же файла
+ALTER TABLE imports
+    ADD COLUMN IF NOT EXISTS forced bool NOT NULL DEFAULT false;
+
+-- прежние повторные загрузки (через force) — все, кроме первой, считаем принудительными
+UPDATE imports i
+SET forced = true
+FROM (
+    SELECT import_id,
+           row_number() OVER (PARTITION BY file_sha256 ORDER BY created_at, import_id) AS n
+    FROM imports
+    WHERE status NOT IN ('FAILED', 'REVERTED')
+) d
+WHERE d.import_id = i.import_id AND d.n > 1;
+
+-- два одновременных импорта одного файла: второй упадёт на индексе, а не пройдёт проверку вместе с первым
+CREATE UNIQUE INDEX IF NOT EXISTS ux_imports_file_sha256_active
+    ON imports(file_sha256)
+    WHERE status NOT IN ('FAILED', 'REVERTED') AND NOT forced;
+
+COMMIT;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+BEGIN;
+
+DROP INDEX IF EXISTS ux_imports_file_sha256_active;
+
+ALTER TABLE imports
+    DROP COLUMN IF EXISTS forced;
+
+COMMIT;
+-- +goose StatementEnd
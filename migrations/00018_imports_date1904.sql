@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+BEGIN;
+
+-- система дат книги Excel (1904 — старые файлы с Mac): серийные номера дат в import_errors.cells
+-- и applications.raw_row без неё не перевести в дату. У прежних импортов неизвестна — считаем 1900
+ALTER TABLE imports
+    ADD COLUMN IF NOT EXISTS date1904 bool NOT NULL DEFAULT false;
+
+COMMIT;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+BEGIN;
+
+ALTER TABLE imports
+    DROP COLUMN IF EXISTS date1904;
+
+COMMIT;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+BEGIN;
+
+-- из candidates_matched — у скольких найденных кандидатов импорт дополнил профиль
+ALTER TABLE imports
+    ADD COLUMN IF NOT EXISTS candidates_updated int NOT NULL DEFAULT 0;
+
+COMMIT;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+BEGIN;
+
+ALTER TABLE imports
+    DROP COLUMN IF EXISTS candidates_updated;
+
+COMMIT;
+-- +goose StatementEnd
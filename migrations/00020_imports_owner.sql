@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+BEGIN;
+
+-- какой процесс ведёт импорт (api, import-watch): при старте api помечает прерванными только свои
+ALTER TABLE imports
+    ADD COLUMN IF NOT EXISTS owner text;
+
+COMMIT;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+BEGIN;
+
+ALTER TABLE imports
+    DROP COLUMN IF EXISTS owner;
+
+COMMIT;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+BEGIN;
+
+-- кто и когда последним вернул DEAD письмо в очередь
+ALTER TABLE email_outbox
+    ADD COLUMN IF NOT EXISTS requeued_by uuid NULL,
+    ADD COLUMN IF NOT EXISTS requeued_at timestamptz NULL;
+
+COMMIT;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+BEGIN;
+
+ALTER TABLE email_outbox
+    DROP COLUMN IF EXISTS requeued_at,
+    DROP COLUMN IF EXISTS requeued_by;
+
+COMMIT;
+-- +goose StatementEnd
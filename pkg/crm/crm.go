@@ -0,0 +1,38 @@
+package crm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Record - одна заявка для выгрузки в CRM (payload из crm_outbox)
+type Record struct {
+	ID      string          // crm_id из outbox, используется как ключ идемпотентности
+	Payload json.RawMessage // json, подготовленный при постановке в очередь
+}
+
+// Response - ответ CRM. StatusCode и Body заполняются и при ошибке, если ответ был получен.
+type Response struct {
+	StatusCode int
+	Body       []byte
+	ExternalID string // идентификатор записи на стороне CRM
+}
+
+// StatusError - CRM ответила не 2xx
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("crm responded %d: %s", e.StatusCode, e.Body)
+}
+
+// Permanent - повторять запрос бессмысленно (ошибка в данных, а не в доступности CRM)
+func (e *StatusError) Permanent() bool {
+	if e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusTooManyRequests {
+		return false
+	}
+	return e.StatusCode >= 400 && e.StatusCode < 500
+}
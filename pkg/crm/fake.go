@@ -0,0 +1,51 @@
+package crm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// FakeClient - CRM в памяти для тестов: в приложении не используется,
+// иначе записи считались бы выгруженными, никуда не уйдя
+type FakeClient struct {
+	mu      sync.Mutex
+	records map[string]json.RawMessage
+	// Fail - если задан, вызывается перед сохранением; ненулевая ошибка возвращается вызывающему
+	Fail func(rec Record) error
+}
+
+func NewFakeClient() *FakeClient {
+	return &FakeClient{records: map[string]json.RawMessage{}}
+}
+
+func (f *FakeClient) Push(_ context.Context, rec Record) (Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Fail != nil {
+		if err := f.Fail(rec); err != nil {
+			return Response{StatusCode: http.StatusServiceUnavailable}, err
+		}
+	}
+
+	f.records[rec.ID] = rec.Payload
+	externalID := uuid.NewString()
+	body, _ := json.Marshal(map[string]string{"id": externalID})
+	return Response{StatusCode: http.StatusCreated, Body: body, ExternalID: externalID}, nil
+}
+
+// Records - копия всего, что было принято
+func (f *FakeClient) Records() map[string]json.RawMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	out := make(map[string]json.RawMessage, len(f.records))
+	for k, v := range f.records {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+package crm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+type HTTPConfig struct {
+	URL     string // endpoint, принимающий POST с payload
+	Token   string // Bearer токен, пусто — без авторизации
+	Timeout time.Duration
+}
+
+// HTTPClient - универсальный адаптер: POST json payload, в ответ ждём 2xx и (опционально) {"id": "..."}
+type HTTPClient struct {
+	cfg    HTTPConfig
+	client *http.Client
+}
+
+func NewHTTPClient(cfg HTTPConfig) *HTTPClient {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 15 * time.Second
+	}
+	return &HTTPClient{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}
+}
+
+func (c *HTTPClient) Push(ctx context.Context, rec Record) (Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.URL, bytes.NewReader(rec.Payload))
+	if err != nil {
+		return Response{}, fmt.Errorf("crm request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Idempotency-Key", rec.ID)
+	if c.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return Response{}, fmt.Errorf("crm do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	res := Response{StatusCode: resp.StatusCode, Body: body}
+	if err != nil {
+		return res, fmt.Errorf("crm read body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return res, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	// CRM может вернуть свой идентификатор под разными именами
+	var ack struct {
+		ID         string `json:"id"`
+		ExternalID string `json:"external_id"`
+	}
+	if len(body) > 0 && json.Unmarshal(body, &ack) == nil {
+		res.ExternalID = ack.ExternalID
+		if res.ExternalID == "" {
+			res.ExternalID = ack.ID
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,176 @@
+package emailaddr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrEmpty        = errors.New("пустой email")
+	ErrInvalid      = errors.New("невалидный email")
+	ErrDomainTypo   = errors.New("опечатка в домене email")
+	ErrLocalTooLong = errors.New("слишком длинное имя ящика")
+	ErrTooLong      = errors.New("слишком длинный email")
+)
+
+const (
+	maxLocalLen = 64
+	maxLen      = 254
+)
+
+// частые опечатки в доменах; письма на них гарантированно не дойдут
+var domainTypos = map[string]string{
+	"gmail.co":   "gmail.com",
+	"gmail.cm":   "gmail.com",
+	"gmail.ru":   "gmail.com",
+	"gmial.com":  "gmail.com",
+	"gmai.com":   "gmail.com",
+	"gamil.com":  "gmail.com",
+	"gnail.com":  "gmail.com",
+	"mail.ry":    "mail.ru",
+	"mail.r":     "mail.ru",
+	"mail.tu":    "mail.ru",
+	"mai.ru":     "mail.ru",
+	"maill.ru":   "mail.ru",
+	"mali.ru":    "mail.ru",
+	"yandex.ry":  "yandex.ru",
+	"yande.ru":   "yandex.ru",
+	"yandeks.ru": "yandex.ru",
+	"yanex.ru":   "yandex.ru",
+	"inbox.ry":   "inbox.ru",
+	"list.ry":    "list.ru",
+	"bk.ry":      "bk.ru",
+	"rambler.ry": "rambler.ru",
+	"hotmail.co": "hotmail.com",
+	"outlook.co": "outlook.com",
+	"icloud.co":  "icloud.com",
+	"yahoo.co":   "yahoo.com",
+	"gmail.com.": "gmail.com",
+	"mail.ru.":   "mail.ru",
+	"yandex.ru.": "yandex.ru",
+	"gmail..com": "gmail.com",
+	"mail..ru":   "mail.ru",
+	"yandex..ru": "yandex.ru",
+}
+
+// домены-синонимы одного ящика.
+// Домены Mail.ru (mail.ru, inbox.ru, list.ru, bk.ru, internet.ru) сюда не входят: это разные
+// ящики, ivan@bk.ru и ivan@mail.ru — два человека. Алиасы Mail.ru выдаются случайными адресами
+// и к основному ящику по адресу не сводятся, опечатки в этих доменах ловит domainTypos.
+var domainAliases = map[string]string{
+	"googlemail.com": "gmail.com",
+	"ya.ru":          "yandex.ru",
+	"yandex.com":     "yandex.ru",
+	"yandex.by":      "yandex.ru",
+	"yandex.kz":      "yandex.ru",
+	"yandex.ua":      "yandex.ru",
+}
+
+// Validate - синтаксическая проверка адреса: одна @, допустимые символы,
+// домен с точкой и буквенной зоной, без пустых частей и двойных точек.
+func Validate(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ErrEmpty
+	}
+	if len(s) > maxLen {
+		return ErrTooLong
+	}
+
+	at := strings.LastIndexByte(s, '@')
+	if at <= 0 || at == len(s)-1 || strings.Count(s, "@") != 1 {
+		return fmt.Errorf("%w: ожидается ровно один символ @", ErrInvalid)
+	}
+	local, domain := s[:at], strings.ToLower(s[at+1:])
+
+	if suggestion, ok := domainTypos[domain]; ok {
+		return fmt.Errorf("%w: %s, возможно %s", ErrDomainTypo, domain, suggestion)
+	}
+
+	if len(local) > maxLocalLen {
+		return ErrLocalTooLong
+	}
+	if strings.HasPrefix(local, ".") || strings.HasSuffix(local, ".") || strings.Contains(local, "..") {
+		return fmt.Errorf("%w: точка в начале, в конце или две точки подряд в имени ящика", ErrInvalid)
+	}
+	for _, ch := range local {
+		if !isLocalChar(ch) {
+			return fmt.Errorf("%w: недопустимый символ %q", ErrInvalid, ch)
+		}
+	}
+
+	for _, ch := range domain {
+		if !(ch >= 'a' && ch <= 'z' || ch >= '0' && ch <= '9' || ch == '-' || ch == '.') {
+			return fmt.Errorf("%w: недопустимый символ %q в домене", ErrInvalid, ch)
+		}
+	}
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return fmt.Errorf("%w: в домене нет точки", ErrInvalid)
+	}
+	for _, l := range labels {
+		if l == "" {
+			return fmt.Errorf("%w: пустая часть домена", ErrInvalid)
+		}
+		if strings.HasPrefix(l, "-") || strings.HasSuffix(l, "-") {
+			return fmt.Errorf("%w: дефис в начале или в конце части домена", ErrInvalid)
+		}
+	}
+	tld := labels[len(labels)-1]
+	if len(tld) < 2 {
+		return fmt.Errorf("%w: слишком короткая доменная зона", ErrInvalid)
+	}
+	for _, ch := range tld {
+		if ch < 'a' || ch > 'z' {
+			return fmt.Errorf("%w: доменная зона должна состоять из букв", ErrInvalid)
+		}
+	}
+	return nil
+}
+
+// Normalize - ключ для сравнения адресов (candidate_contacts.normalized):
+// нижний регистр, синонимы доменов, для gmail без точек и +меток,
+// для яндекса без +меток и с точками, заменёнными на дефисы.
+// Для невалидного адреса — просто нижний регистр.
+func Normalize(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if Validate(s) != nil {
+		return s
+	}
+
+	at := strings.LastIndexByte(s, '@')
+	local, domain := s[:at], s[at+1:]
+	if alias, ok := domainAliases[domain]; ok {
+		domain = alias
+	}
+
+	switch domain {
+	case "gmail.com":
+		local = stripTag(local)
+		local = strings.ReplaceAll(local, ".", "")
+	case "yandex.ru":
+		local = stripTag(local)
+		local = strings.ReplaceAll(local, ".", "-")
+	}
+	if local == "" {
+		return s
+	}
+	return local + "@" + domain
+}
+
+func stripTag(local string) string {
+	if i := strings.IndexByte(local, '+'); i >= 0 {
+		return local[:i]
+	}
+	return local
+}
+
+// RFC 5322 atext без кавычек
+func isLocalChar(ch rune) bool {
+	switch {
+	case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9':
+		return true
+	}
+	return strings.ContainsRune(".!#$%&'*+/=?^_`{|}~-", ch)
+}
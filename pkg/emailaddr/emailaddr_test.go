@@ -0,0 +1,69 @@
+package emailaddr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	valid := []string{
+		"ivan.petrov@mail.ru",
+		" Ivan_Petrov+hh@Gmail.com ",
+		"o'connor@example.co.uk",
+		"student-2025@spbu.ru",
+	}
+	for _, s := range valid {
+		if err := Validate(s); err != nil {
+			t.Errorf("Validate(%q) = %v, want nil", s, err)
+		}
+	}
+
+	cases := []struct {
+		in   string
+		want error
+	}{
+		{"  ", ErrEmpty},
+		{"name@gmail,com", ErrInvalid},
+		{"name.mail.ru", ErrInvalid},
+		{"name@@mail.ru", ErrInvalid},
+		{"name.@mail.ru", ErrInvalid},
+		{"na..me@mail.ru", ErrInvalid},
+		{"name@mail", ErrInvalid},
+		{"name@mail.r1", ErrInvalid},
+		{"name@-mail.ru", ErrInvalid},
+		{"name@mail.ru.", ErrDomainTypo},
+		{"name@gmial.com", ErrDomainTypo},
+		{"name@mail.ry", ErrDomainTypo},
+		{"имя@mail.ru", ErrInvalid},
+	}
+	for _, c := range cases {
+		if err := Validate(c.in); !errors.Is(err, c.want) {
+			t.Errorf("Validate(%q) = %v, want %v", c.in, err, c.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{" Ivan.Petrov@Mail.RU ", "ivan.petrov@mail.ru"},
+		{"ivan.petrov+hh@gmail.com", "ivanpetrov@gmail.com"},
+		{"Ivan.Petrov@googlemail.com", "ivanpetrov@gmail.com"},
+		{"ivan.petrov+hh@yandex.ru", "ivan-petrov@yandex.ru"},
+		{"ivan.petrov@ya.ru", "ivan-petrov@yandex.ru"},
+		{"ivan-petrov@yandex.kz", "ivan-petrov@yandex.ru"},
+		// разные ящики Mail.ru не склеиваются, метки не снимаются
+		{"ivan@bk.ru", "ivan@bk.ru"},
+		{"ivan+hh@inbox.ru", "ivan+hh@inbox.ru"},
+		{"ivan.petrov+hh@example.com", "ivan.petrov+hh@example.com"},
+		// невалидный адрес только приводится к нижнему регистру
+		{"Name@Gmail,com", "name@gmail,com"},
+		{"+hh@gmail.com", "+hh@gmail.com"},
+	}
+	for _, c := range cases {
+		if got := Normalize(c.in); got != c.want {
+			t.Errorf("Normalize(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Package jwt - токены JWT с подписью HS256 (HMAC-SHA256) на стандартной библиотеке.
+// Другие алгоритмы сервис не выпускает и не принимает.
+package jwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMalformed = errors.New("malformed token")
+	ErrAlgorithm = errors.New("unsupported token algorithm")
+	ErrSignature = errors.New("invalid token signature")
+	ErrExpired   = errors.New("token expired")
+)
+
+const algHS256 = "HS256"
+
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+}
+
+type Claims struct {
+	Subject   string `json:"sub"`
+	Issuer    string `json:"iss,omitempty"`
+	Audience  string `json:"aud,omitempty"` // для чего токен; пустой — обычный токен входа
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+var enc = base64.RawURLEncoding
+
+// Sign - header.payload.signature
+func Sign(c Claims, key []byte) (string, error) {
+	h, err := json.Marshal(header{Alg: algHS256, Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	p, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	signed := enc.EncodeToString(h) + "." + enc.EncodeToString(p)
+	return signed + "." + enc.EncodeToString(sign(signed, key)), nil
+}
+
+// Parse - проверка подписи и срока действия; токен без exp не принимается
+func Parse(token string, key []byte, now time.Time) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, ErrMalformed
+	}
+
+	var h header
+	if err := decode(parts[0], &h); err != nil {
+		return Claims{}, err
+	}
+	// alg из заголовка не выбирает алгоритм проверки, а только сверяется с ожидаемым
+	if h.Alg != algHS256 {
+		return Claims{}, ErrAlgorithm
+	}
+
+	sig, err := enc.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, ErrMalformed
+	}
+	if !hmac.Equal(sig, sign(parts[0]+"."+parts[1], key)) {
+		return Claims{}, ErrSignature
+	}
+
+	var c Claims
+	if err = decode(parts[1], &c); err != nil {
+		return Claims{}, err
+	}
+	if c.ExpiresAt == 0 || now.Unix() >= c.ExpiresAt {
+		return Claims{}, ErrExpired
+	}
+	return c, nil
+}
+
+func sign(s string, key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(s))
+	return mac.Sum(nil)
+}
+
+func decode(part string, v any) error {
+	b, err := enc.DecodeString(part)
+	if err != nil {
+		return ErrMalformed
+	}
+	if err = json.Unmarshal(b, v); err != nil {
+		return ErrMalformed
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package jwt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	testKey = []byte("test-key")
+	testNow = time.Unix(1760000000, 0)
+)
+
+// token - header.payload с подписью HS256 тем же ключом: меняется только то, что проверяет случай
+func token(t *testing.T, header, payload string) string {
+	t.Helper()
+	signed := enc.EncodeToString([]byte(header)) + "." + enc.EncodeToString([]byte(payload))
+	return signed + "." + enc.EncodeToString(sign(signed, testKey))
+}
+
+func TestSignParse(t *testing.T) {
+	want := Claims{Subject: "user-1", Issuer: "x5", IssuedAt: testNow.Unix(), ExpiresAt: testNow.Add(time.Hour).Unix()}
+	tok, err := Sign(want, testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Parse(tok, testKey, testNow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("claims = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	valid, err := Sign(Claims{Subject: "user-1", ExpiresAt: testNow.Add(time.Hour).Unix()}, testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(valid, ".")
+	payload := `{"sub":"user-1","exp":1760003600}`
+
+	cases := []struct {
+		name  string
+		token string
+		key   []byte
+		want  error
+	}{
+		{"alg none", enc.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + ".", testKey, ErrAlgorithm},
+		{"alg HS512", token(t, `{"alg":"HS512","typ":"JWT"}`, payload), testKey, ErrAlgorithm},
+		{"изменён payload", parts[0] + "." + enc.EncodeToString([]byte(`{"sub":"admin","exp":1760003600}`)) + "." + parts[2], testKey, ErrSignature},
+		{"другой ключ", valid, []byte("other-key"), ErrSignature},
+		{"без exp", token(t, `{"alg":"HS256"}`, `{"sub":"user-1"}`), testKey, ErrExpired},
+		{"истёк", token(t, `{"alg":"HS256"}`, `{"sub":"user-1","exp":1759999999}`), testKey, ErrExpired},
+		{"истекает сейчас", token(t, `{"alg":"HS256"}`, `{"sub":"user-1","exp":1760000000}`), testKey, ErrExpired},
+		{"два сегмента", parts[0] + "." + parts[1], testKey, ErrMalformed},
+		{"четыре сегмента", valid + ".x", testKey, ErrMalformed},
+		{"пустой", "", testKey, ErrMalformed},
+		{"заголовок не base64", "!!!." + parts[1] + "." + parts[2], testKey, ErrMalformed},
+		{"подпись не base64", parts[0] + "." + parts[1] + ".***", testKey, ErrMalformed},
+		{"payload не JSON", token(t, `{"alg":"HS256"}`, `not json`), testKey, ErrMalformed},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := Parse(c.token, c.key, testNow); !errors.Is(err, c.want) {
+				t.Errorf("Parse = %v, want %v", err, c.want)
+			}
+		})
+	}
+}
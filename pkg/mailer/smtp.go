@@ -0,0 +1,140 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type SMTPConfig struct {
+	Addr     string // host:port
+	Username string // пусто — без авторизации (mailhog и т.п.)
+	Password string
+	From     string
+	Timeout  time.Duration
+}
+
+// SMTPSender - отправка писем через обычный SMTP сервер
+type SMTPSender struct {
+	cfg SMTPConfig
+}
+
+func NewSMTPSender(cfg SMTPConfig) *SMTPSender {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	return &SMTPSender{cfg: cfg}
+}
+
+// Send - отправляет письмо и возвращает Message-ID, который мы сами проставили в заголовок
+func (s *SMTPSender) Send(ctx context.Context, msg Message) (string, error) {
+	host, _, err := net.SplitHostPort(s.cfg.Addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid smtp addr %q: %w", s.cfg.Addr, err)
+	}
+	// From может быть с именем ("X5 Group <noreply@...>"): в конверт идёт только адрес
+	from, err := mail.ParseAddress(s.cfg.From)
+	if err != nil {
+		return "", fmt.Errorf("invalid smtp from %q: %w", s.cfg.From, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	d := net.Dialer{}
+	conn, err := d.DialContext(ctx, "tcp", s.cfg.Addr)
+	if err != nil {
+		return "", fmt.Errorf("smtp dial: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return "", fmt.Errorf("smtp client: %w", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err = c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return "", fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+	if s.cfg.Username != "" {
+		if err = c.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, host)); err != nil {
+			return "", fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+
+	if err = c.Mail(from.Address); err != nil {
+		return "", fmt.Errorf("smtp mail from: %w", err)
+	}
+	if err = c.Rcpt(msg.To); err != nil {
+		return "", fmt.Errorf("smtp rcpt to: %w", err)
+	}
+
+	messageID := fmt.Sprintf("<%s@%s>", uuid.NewString(), domainOf(from.Address, host))
+
+	w, err := c.Data()
+	if err != nil {
+		return "", fmt.Errorf("smtp data: %w", err)
+	}
+	if _, err = w.Write(buildMessage(from.String(), msg, messageID)); err != nil {
+		_ = w.Close()
+		return "", fmt.Errorf("smtp write: %w", err)
+	}
+	if err = w.Close(); err != nil {
+		return "", fmt.Errorf("smtp data close: %w", err)
+	}
+
+	_ = c.Quit()
+	return messageID, nil
+}
+
+func buildMessage(from string, msg Message, messageID string) []byte {
+	var b bytes.Buffer
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", msg.Subject) + "\r\n")
+	b.WriteString("Message-ID: " + messageID + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n")
+	b.WriteString("\r\n")
+
+	// base64 по 76 символов в строке (RFC 2045)
+	enc := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(enc) > 76 {
+		b.WriteString(enc[:76] + "\r\n")
+		enc = enc[76:]
+	}
+	b.WriteString(enc + "\r\n")
+
+	return b.Bytes()
+}
+
+func domainOf(from, fallback string) string {
+	if i := strings.LastIndex(from, "@"); i >= 0 && i < len(from)-1 {
+		return from[i+1:]
+	}
+	return fallback
+}
@@ -0,0 +1,134 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// smtpStandIn - минимальный SMTP сервер: запоминает конверт и данные письма
+type smtpStandIn struct {
+	ln net.Listener
+
+	mu       sync.Mutex
+	mailFrom string
+	rcptTo   string
+	data     string
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &smtpStandIn{ln: ln}
+	t.Cleanup(func() { _ = ln.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *smtpStandIn) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP stand-in")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			s.mu.Lock()
+			s.mailFrom = line[len("MAIL FROM:"):]
+			s.mu.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			s.mu.Lock()
+			s.rcptTo = line[len("RCPT TO:"):]
+			s.mu.Unlock()
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 go ahead")
+			var b strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				b.WriteString(l)
+			}
+			s.mu.Lock()
+			s.data = b.String()
+			s.mu.Unlock()
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSMTPSenderSend(t *testing.T) {
+	srv := newSMTPStandIn(t)
+	sender := NewSMTPSender(SMTPConfig{
+		Addr:    srv.ln.Addr().String(),
+		From:    "X5 Group <noreply@x5-intern-hiring.local>",
+		Timeout: 5 * time.Second,
+	})
+
+	id, err := sender.Send(context.Background(), Message{To: "student@example.ru", Subject: "Приглашение", Body: "Здравствуйте!"})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if !strings.HasSuffix(id, "@x5-intern-hiring.local>") {
+		t.Errorf("message id = %q, want domain of from address", id)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.mailFrom != "<noreply@x5-intern-hiring.local>" {
+		t.Errorf("MAIL FROM = %q, want bare address", srv.mailFrom)
+	}
+	if srv.rcptTo != "<student@example.ru>" {
+		t.Errorf("RCPT TO = %q", srv.rcptTo)
+	}
+	if !strings.Contains(srv.data, "From: \"X5 Group\" <noreply@x5-intern-hiring.local>\r\n") {
+		t.Errorf("From header with display name not found in:\n%s", srv.data)
+	}
+	if !strings.Contains(srv.data, "Message-ID: "+id+"\r\n") {
+		t.Errorf("Message-ID header not found in:\n%s", srv.data)
+	}
+}
+
+func TestSMTPSenderInvalidFrom(t *testing.T) {
+	sender := NewSMTPSender(SMTPConfig{Addr: "127.0.0.1:1", From: "X5 Group <noreply"})
+	if _, err := sender.Send(context.Background(), Message{To: "student@example.ru"}); err == nil {
+		t.Fatal("expected error for unparsable from")
+	}
+}
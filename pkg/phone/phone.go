@@ -0,0 +1,98 @@
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrEmpty         = errors.New("пустой номер телефона")
+	ErrInvalidLength = errors.New("неверная длина номера телефона")
+	ErrInvalidChars  = errors.New("недопустимые символы в номере телефона")
+)
+
+// длина национальной части для кодов стран СНГ
+var nationalLength = map[string]int{
+	"7":   10, // Россия, Казахстан
+	"373": 8,  // Молдова
+	"374": 8,  // Армения
+	"375": 9,  // Беларусь
+	"380": 9,  // Украина
+	"992": 9,  // Таджикистан
+	"993": 8,  // Туркменистан
+	"994": 9,  // Азербайджан
+	"995": 9,  // Грузия
+	"996": 9,  // Киргизия
+	"998": 9,  // Узбекистан
+}
+
+// E.164: не больше 15 цифр вместе с кодом страны
+const (
+	minE164Digits = 8
+	maxE164Digits = 15
+)
+
+// Normalize - номер в формате E.164 (+79161234567).
+// Без "+" номер считается российским: 8XXXXXXXXXX и 7XXXXXXXXXX → +7XXXXXXXXXX,
+// 10 цифр → +7XXXXXXXXXX, 80XXXXXXXXX — белорусский формат с 8 → +375XXXXXXXXX.
+// Номера с кодом страны из СНГ проверяются по длине национальной части.
+func Normalize(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", ErrEmpty
+	}
+
+	plus := false
+	var b strings.Builder
+	for i, ch := range s {
+		switch {
+		case ch >= '0' && ch <= '9':
+			b.WriteRune(ch)
+		case ch == '+' && b.Len() == 0 && !plus:
+			plus = true
+		case ch == ' ' || ch == '-' || ch == '(' || ch == ')' || ch == '.' || ch == '\u00a0':
+		default:
+			return "", fmt.Errorf("%w: %q на позиции %d", ErrInvalidChars, ch, i)
+		}
+	}
+	digits := b.String()
+	if digits == "" {
+		return "", ErrEmpty
+	}
+
+	if !plus {
+		switch {
+		case len(digits) == 11 && strings.HasPrefix(digits, "80"):
+			digits = "375" + digits[2:]
+		case len(digits) == 11 && (digits[0] == '8' || digits[0] == '7'):
+			digits = "7" + digits[1:]
+		case len(digits) == 10:
+			digits = "7" + digits
+		}
+	}
+
+	if code := countryCode(digits); code != "" {
+		if len(digits)-len(code) != nationalLength[code] {
+			return "", fmt.Errorf("%w: %s", ErrInvalidLength, s)
+		}
+		return "+" + digits, nil
+	}
+
+	if len(digits) < minE164Digits || len(digits) > maxE164Digits {
+		return "", fmt.Errorf("%w: %s", ErrInvalidLength, s)
+	}
+	return "+" + digits, nil
+}
+
+func countryCode(digits string) string {
+	for _, n := range []int{3, 1} {
+		if len(digits) < n {
+			continue
+		}
+		if _, ok := nationalLength[digits[:n]]; ok {
+			return digits[:n]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		name, in, want string
+	}{
+		{"ru с восьмёркой", "8 (916) 123-45-67", "+79161234567"},
+		{"ru с семёркой без плюса", "79161234567", "+79161234567"},
+		{"ru с плюсом", "+7 916 123 45 67", "+79161234567"},
+		{"ru десять цифр", "9161234567", "+79161234567"},
+		{"ru с точками и nbsp", "8.916.123 45 67", "+79161234567"},
+		{"kz", "+7 701 123 45 67", "+77011234567"},
+		{"by с восьмёркой", "80291234567", "+375291234567"},
+		{"by с плюсом", "+375 (29) 123-45-67", "+375291234567"},
+		{"by без плюса", "375291234567", "+375291234567"},
+		{"ua", "+380 44 123 4567", "+380441234567"},
+		{"uz", "+998 90 123 45 67", "+998901234567"},
+		{"am", "+374 10 123456", "+37410123456"},
+		{"не снг", "+49 30 1234567", "+49301234567"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := Normalize(c.in)
+			if err != nil {
+				t.Fatalf("Normalize(%q): %v", c.in, err)
+			}
+			if got != c.want {
+				t.Errorf("Normalize(%q) = %q, want %q", c.in, got, c.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeErrors(t *testing.T) {
+	cases := []struct {
+		name, in string
+		want     error
+	}{
+		{"пусто", "  ", ErrEmpty},
+		{"только скобки", "()", ErrEmpty},
+		{"буквы", "8916abc4567", ErrInvalidChars},
+		{"плюс в середине", "8916+1234567", ErrInvalidChars},
+		{"ru короткий", "+7 916 123 45 6", ErrInvalidLength},
+		{"ru длинный", "+7 916 123 45 678", ErrInvalidLength},
+		{"by короткий", "+375 29 123 45 6", ErrInvalidLength},
+		{"слишком короткий", "12345", ErrInvalidLength},
+		{"длиннее e164", "+1234567890123456", ErrInvalidLength},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := Normalize(c.in)
+			if !errors.Is(err, c.want) {
+				t.Errorf("Normalize(%q) = %q, %v; want error %v", c.in, got, err, c.want)
+			}
+		})
+	}
+}